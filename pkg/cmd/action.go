@@ -8,8 +8,9 @@ import (
 	"os"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
-
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/olekukonko/tablewriter"
@@ -27,7 +28,8 @@ vra-cli get action
 # Get an action by ID:
 vra-cli get action --id bb3f6aff-311a-45fe-8081-5845a529068d`,
 	Run: func(cmd *cobra.Command, args []string) {
-
+		// The table only needs the fields returned in the action list
+		APIClient.Summary = APIClient.Output == "table"
 		response, err := orchestrator.GetAction(APIClient, id, category, name)
 		if err != nil {
 			log.Errorln("Unable to get actions: ", err)
@@ -46,15 +48,14 @@ vra-cli get action --id bb3f6aff-311a-45fe-8081-5845a529068d`,
 				}
 				table.Render()
 			} else if APIClient.Output == "export" {
-				// Export the Worfklow
-				for _, action := range response {
-					err := orchestrator.ExportAction(APIClient, action.ID, action.Name, exportPath)
-					if err != nil {
-						log.Warnln("Unable to export action: ", err)
-					} else {
-						log.Infoln("Action", action.Name, "exported")
-					}
+				// Export the Actions
+				names := make([]string, len(response))
+				for i, action := range response {
+					names[i] = action.Name
 				}
+				exportObjects(names, "Actions", func(client *types.APIClientOptions, i int) error {
+					return orchestrator.ExportAction(client, response[i].ID, response[i].Name, exportPath)
+				})
 
 			} else {
 				helpers.PrettyPrint(response)
@@ -86,19 +87,10 @@ var createActionCmd = &cobra.Command{
 	Short: "Create a Action",
 	Long:  `Create a Action`,
 	Run: func(cmd *cobra.Command, args []string) {
-		for _, path := range helpers.GetFilePaths(importPath, ".action") {
-			log.Infoln("Importing action:", path)
-			err := orchestrator.ImportAction(APIClient, path, category)
-			if err != nil {
-				log.Errorln("Unable to import action: ", err)
-			} else {
-				action, err := orchestrator.GetAction(APIClient, "", category, name)
-				if err != nil {
-					log.Errorln("Action imported OK, but I'm unable to get imported action details: ", err)
-				}
-				log.Infoln("Action imported:", action[0].Name, "with ID:", action[0].ID)
-			}
-		}
+		paths := helpers.GetFilePaths(importPath, ".action")
+		reportImport(importOrchestratorPaths(manifest.KindAction, paths, orchestratorPolicy(), func(APIClient *types.APIClientOptions, path string) error {
+			return orchestrator.ImportAction(APIClient, path, category)
+		}))
 
 	},
 }
@@ -120,4 +112,7 @@ func init() {
 	createCmd.AddCommand(createActionCmd)
 	createActionCmd.Flags().StringVarP(&category, "category", "c", "", "Category to import")
 	createActionCmd.Flags().StringVar(&importPath, "importPath", "", "Path to the zip file, or folder containing zip files, to import")
+	// Completions
+	registerCompletions(getActionCmd, "action")
+	registerCompletions(delActionCmd, "action")
 }
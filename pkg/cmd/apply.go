@@ -0,0 +1,629 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/cloudassembly"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
+	"github.com/sammcgeown/vra-cli/pkg/util/backup"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	jsonschema "github.com/sammcgeown/vra-cli/pkg/util/schema"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+var manifestPath string
+
+// projectManifest - the fields of a Project manifest
+type projectManifest struct {
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	Administrators        []string `json:"administrators"`
+	Members               []string `json:"members"`
+	Viewers               []string `json:"viewers"`
+	OperationTimeout      int64    `json:"operationTimeout"`
+	MachineNamingTemplate string   `json:"machineNamingTemplate"`
+	SharedResources       bool     `json:"sharedResources"`
+}
+
+// cloudTemplateManifest - the fields of a Cloud Template manifest
+type cloudTemplateManifest struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Project         string `json:"project"`
+	Content         string `json:"content"`
+	RequestScopeOrg bool   `json:"requestScopeOrg"`
+}
+
+// applyResult - the outcome of applying a manifest document
+type applyResult struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Project string `json:"project"`
+	Result  string `json:"result"`
+	Error   string `json:"error,omitempty"`
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update objects from manifests",
+	Long: `Create or update objects from a file, or a directory, of multi-document YAML manifests.
+
+Each document has a kind - Project, Variable, Endpoint, CustomIntegration, PropertyGroup, Package,
+Action, Workflow, CloudTemplate or Pipeline. Pipelines and Endpoints use the Code Stream export format,
+Variables the format written by get variable --exportPath. Workflows, Actions and Packages reference
+the exported file, relative to the manifest:
+
+kind: Workflow
+file: workflows/My Workflow.zip
+category: My/Workflow/Category
+
+Documents are applied in dependency order (Projects first, Pipelines last, nested Pipelines before
+the Pipelines that run them), and objects that already exist are updated.
+
+# Apply a directory of manifests
+vra-cli apply -f ./manifests`,
+	Run: func(cmd *cobra.Command, args []string) {
+		documents, err := manifest.Load(manifestPath)
+		if err != nil {
+			log.Fatalln("Unable to load manifests:", err)
+		}
+		if len(documents) == 0 {
+			log.Warnln("No manifests were found in", manifestPath)
+			return
+		}
+		applyDocuments(documents, nil)
+	},
+}
+
+// applyDocuments applies the documents in dependency order and prints the results, including
+// any objects that were skipped before they could be applied
+func applyDocuments(documents []*manifest.Document, skipped []backup.Object) {
+	results, failed, err := runDocuments(documents, skipped)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	printApplyResults(results)
+	if failed > 0 {
+		log.Fatalln(failed, "of", len(documents), "objects failed to apply")
+	}
+}
+
+// runDocuments applies the documents in dependency order, and returns the results and the number of
+// documents that failed. An error is returned if the documents could not be checked or ordered.
+func runDocuments(documents []*manifest.Document, skipped []backup.Object) ([]applyResult, int, error) {
+	checked := make(map[string]bool)
+	for _, document := range documents {
+		if !checked[document.Source] {
+			if err := integrity.Check(APIClient.Integrity, document.Source); err != nil {
+				return nil, 0, err
+			}
+			checked[document.Source] = true
+		}
+	}
+	if err := validateDocuments(documents); err != nil {
+		return nil, 0, err
+	}
+	if err := manifest.Sort(documents); err != nil {
+		return nil, 0, err
+	}
+
+	results := importDocuments(documents, onConflict(conflict.Overwrite))
+	failed := 0
+	for _, r := range results {
+		if r.Result == "failed" {
+			failed++
+		}
+	}
+	for _, o := range skipped {
+		results = append(results, applyResult{Kind: o.Kind, Name: o.Name, Project: o.Project, Result: "skipped", Error: o.Reason})
+	}
+	return results, failed, nil
+}
+
+// validateDocuments validates the documents of each kind with a schema (see jsonschema.Kinds) against it
+func validateDocuments(documents []*manifest.Document) error {
+	schemas := make(map[string]*jsonschema.Schema)
+	var problems []string
+	for _, document := range documents {
+		s, ok := schemas[document.Kind]
+		if !ok {
+			s, _ = jsonschema.For(document.Kind) // nil if there is no schema for the kind
+			schemas[document.Kind] = s
+		}
+		if s == nil {
+			continue
+		}
+		errs, err := s.ValidateYAML(document.Raw)
+		if err != nil {
+			return err
+		}
+		for _, e := range errs {
+			if e.UnsupportedTaskType() { // Left for Code Stream to check, as vra-cli lint does
+				log.Warnln(document, e.Error())
+				continue
+			}
+			problems = append(problems, document.String()+" ("+document.Source+"): "+e.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("documents do not match their schema:\n" + strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// printApplyResults prints the results of applying documents as a table, or JSON
+func printApplyResults(results []applyResult) {
+	if APIClient.Output == "json" {
+		helpers.PrettyPrint(results)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Project", "Result"})
+	outcomes := make([]batch.Result, len(results))
+	for i, r := range results {
+		table.Append([]string{r.Kind, r.Name, r.Project, r.Result})
+		outcomes[i].Status = r.Result
+	}
+	table.SetFooter([]string{"", "", "", batch.Summary(outcomes)})
+	table.Render()
+}
+
+// applyDocument creates the object described by a manifest document, or resolves the conflict with
+// an existing object using the policy (see conflict.Policies)
+func applyDocument(APIClient *types.APIClientOptions, document *manifest.Document, policy string) (string, error) {
+	switch document.Kind {
+	case manifest.KindWorkflow, manifest.KindAction, manifest.KindPackage:
+		return importOrchestratorFile(APIClient, policy, document.Kind, func() error { return applyOrchestratorFile(APIClient, document) })
+	}
+	original := document.Name
+	if policy != conflict.Overwrite {
+		exists := func(name string) (bool, error) {
+			id, err := findDocument(APIClient, document, name)
+			return id != "", err
+		}
+		found, err := exists(document.Name)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			switch policy {
+			case conflict.Skip:
+				return "skipped", nil
+			case conflict.Rename:
+				name, err := conflict.NewName(document.Name, exists)
+				if err != nil {
+					return "", err
+				}
+				if err := document.Rename(name); err != nil {
+					return "", err
+				}
+			default:
+				return "", conflict.ErrExists
+			}
+		}
+	}
+	action, err := createOrUpdateDocument(APIClient, document)
+	if err == nil && document.Name != original {
+		action = "created as " + document.Name
+	}
+	return action, err
+}
+
+// createOrUpdateDocument creates or updates the object described by a manifest document
+func createOrUpdateDocument(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	switch document.Kind {
+	case manifest.KindProject:
+		return applyProject(APIClient, document)
+	case manifest.KindVariable:
+		return applyVariable(APIClient, document)
+	case manifest.KindEndpoint:
+		endpoints, err := codestream.GetEndpoint(APIClient, "", document.Name, document.Project, "", "")
+		if err != nil {
+			return "", err
+		}
+		return applyCodeStreamYaml(APIClient, document, len(endpoints) > 0, "endpoint")
+	case manifest.KindPipeline:
+		pipelines, err := codestream.GetPipeline(APIClient, "", document.Name, document.Project, "")
+		if err != nil {
+			return "", err
+		}
+		return applyCodeStreamYaml(APIClient, document, len(pipelines) > 0, "pipeline")
+	case manifest.KindCustomIntegration:
+		return applyCustomIntegration(APIClient, document)
+	case manifest.KindCloudTemplate:
+		return applyCloudTemplate(APIClient, document)
+	case manifest.KindPropertyGroup:
+		return applyPropertyGroup(APIClient, document)
+	}
+	return "", errors.New("unsupported kind " + document.Kind)
+}
+
+// findDocument returns the ID of the object a document describes, with the given name, or an empty string
+// if it does not exist. vRO Workflows, Actions and Packages are not looked up.
+func findDocument(APIClient *types.APIClientOptions, document *manifest.Document, name string) (string, error) {
+	switch document.Kind {
+	case manifest.KindProject:
+		projects, err := cloudassembly.GetProject(APIClient, name, "")
+		if err != nil || len(projects) == 0 {
+			return "", err
+		}
+		return *projects[0].ID, nil
+	case manifest.KindVariable:
+		variables, err := codestream.GetVariable(APIClient, "", name, document.Project, "")
+		if err != nil || len(variables) == 0 {
+			return "", err
+		}
+		return variables[0].ID, nil
+	case manifest.KindEndpoint:
+		endpoints, err := codestream.GetEndpoint(APIClient, "", name, document.Project, "", "")
+		if err != nil || len(endpoints) == 0 {
+			return "", err
+		}
+		return endpoints[0].ID, nil
+	case manifest.KindPipeline:
+		pipelines, err := codestream.GetPipeline(APIClient, "", name, document.Project, "")
+		if err != nil || len(pipelines) == 0 {
+			return "", err
+		}
+		return pipelines[0].ID, nil
+	case manifest.KindCustomIntegration:
+		customIntegrations, err := codestream.GetCustomIntegration(APIClient, "", name)
+		if err != nil || len(customIntegrations) == 0 {
+			return "", err
+		}
+		return customIntegrations[0].ID, nil
+	case manifest.KindCloudTemplate:
+		templates, err := cloudassembly.GetCloudTemplate(APIClient, "", name, document.Project)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range templates {
+			if t.Name == name {
+				return t.ID, nil
+			}
+		}
+		return "", nil
+	case manifest.KindPropertyGroup:
+		propertyGroups, err := cloudassembly.GetPropertyGroups(APIClient, "", name, document.Project)
+		if err != nil {
+			return "", err
+		}
+		for _, pg := range propertyGroups {
+			if pg.Name == name {
+				return pg.ID, nil
+			}
+		}
+		return "", nil
+	case manifest.KindWorkflow, manifest.KindAction, manifest.KindPackage:
+		return "", errors.New(document.Kind + " objects cannot be looked up by vra-cli")
+	}
+	return "", errors.New("unsupported kind " + document.Kind)
+}
+
+// deleteDocument deletes the object described by a manifest document, if it exists. vRO Workflows,
+// Actions and Packages are not deleted.
+func deleteDocument(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	id, err := findDocument(APIClient, document, document.Name)
+	if err != nil || id == "" {
+		return "not found", err
+	}
+	switch document.Kind {
+	case manifest.KindProject:
+		err = cloudassembly.DeleteProject(APIClient, id)
+	case manifest.KindVariable:
+		_, err = codestream.DeleteVariable(APIClient, id)
+	case manifest.KindEndpoint:
+		err = codestream.DeleteEndpoint(APIClient, id)
+	case manifest.KindPipeline:
+		_, err = codestream.DeletePipeline(APIClient, id)
+	case manifest.KindCustomIntegration:
+		err = codestream.DeleteCustomIntegration(APIClient, id, "")
+	case manifest.KindCloudTemplate:
+		err = cloudassembly.DeleteCloudTemplate(APIClient, id)
+	case manifest.KindPropertyGroup:
+		err = cloudassembly.DeletePropertyGroup(APIClient, id)
+	}
+	return "deleted", err
+}
+
+// snapshotDocument returns a document describing the object a document would overwrite, as it is now,
+// or nil if it does not exist. vRO Workflows, Actions and Packages are not snapshotted.
+func snapshotDocument(APIClient *types.APIClientOptions, document *manifest.Document) (*manifest.Document, error) {
+	var object interface{}
+	switch document.Kind {
+	case manifest.KindProject:
+		projects, err := cloudassembly.GetProject(APIClient, document.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			if p.Name == document.Name {
+				object = projectManifest{
+					Name:                  p.Name,
+					Description:           p.Description,
+					Administrators:        userEmails(p.Administrators),
+					Members:               userEmails(p.Members),
+					Viewers:               userEmails(p.Viewers),
+					OperationTimeout:      p.OperationTimeout,
+					MachineNamingTemplate: p.MachineNamingTemplate,
+					SharedResources:       p.SharedResources,
+				}
+			}
+		}
+	case manifest.KindVariable:
+		variables, err := codestream.GetVariable(APIClient, "", document.Name, document.Project, "")
+		if err != nil || len(variables) == 0 {
+			return nil, err
+		}
+		v := variables[0]
+		if v.Type == "SECRET" || v.Type == "RESTRICTED" {
+			return nil, errors.New("the value of " + v.Type + " Variable " + v.Name + " is not returned by the API")
+		}
+		object = types.VariableRequest{Project: v.Project, Name: v.Name, Description: v.Description, Type: v.Type, Value: v.Value}
+	case manifest.KindEndpoint, manifest.KindPipeline:
+		id, err := findDocument(APIClient, document, document.Name)
+		if err != nil || id == "" {
+			return nil, err
+		}
+		content, err := codestream.GetExportYaml(APIClient, document.Name, document.Project, strings.ToLower(document.Kind))
+		if err != nil {
+			return nil, err
+		}
+		snapshots, err := manifest.Parse(document.Source, content)
+		if err != nil || len(snapshots) == 0 {
+			return nil, err
+		}
+		return snapshots[0], nil
+	case manifest.KindCustomIntegration:
+		customIntegrations, err := codestream.GetCustomIntegration(APIClient, "", document.Name)
+		if err != nil || len(customIntegrations) == 0 {
+			return nil, err
+		}
+		ci := customIntegrations[0]
+		object = map[string]interface{}{"name": ci.Name, "description": ci.Description, "yaml": ci.Yaml}
+	case manifest.KindCloudTemplate:
+		templates, err := cloudassembly.GetCloudTemplate(APIClient, "", document.Name, document.Project)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range templates {
+			if t.Name == document.Name {
+				object = cloudTemplateManifest{Name: t.Name, Description: t.Description, Project: document.Project, Content: t.Content, RequestScopeOrg: t.RequestScopeOrg}
+			}
+		}
+	case manifest.KindPropertyGroup:
+		propertyGroups, err := cloudassembly.GetPropertyGroups(APIClient, "", document.Name, document.Project)
+		if err != nil {
+			return nil, err
+		}
+		for _, pg := range propertyGroups {
+			if pg.Name == document.Name {
+				fields, err := backupFields(pg, "id", "orgId", "projectId", "projectName", "createdAt", "createdBy", "updatedAt", "updatedBy")
+				if err != nil {
+					return nil, err
+				}
+				fields["project"] = document.Project
+				object = fields
+			}
+		}
+	}
+	if object == nil {
+		return nil, nil
+	}
+	return manifest.New(document.Kind, document.Source, object)
+}
+
+// deleteDocuments deletes the objects described by the documents concurrently, a level of dependencies
+// at a time, deleting the objects that depend on others first
+func deleteDocuments(documents []*manifest.Document) []applyResult {
+	results := make([]applyResult, len(documents))
+	clients := workerClients(len(documents))
+	levels := manifest.Levels(documents)
+	for l := len(levels) - 1; l >= 0; l-- {
+		level := levels[l]
+		names := make([]string, len(level))
+		for i, index := range level {
+			names[i] = documents[index].String()
+		}
+		batch.Run("Deleting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			document := documents[level[i]]
+			result := applyResult{Kind: document.Kind, Name: document.Name, Project: document.Project}
+			action, err := deleteDocument(clients[worker], document)
+			if err != nil {
+				log.Errorln("Unable to delete", document, err)
+				result.Result, result.Error = "failed", err.Error()
+			} else {
+				result.Result = action
+			}
+			results[level[i]] = result
+			return action, err
+		})
+	}
+	return results
+}
+
+// applyProject creates or updates a Project
+func applyProject(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	var p projectManifest
+	if err := document.Decode(&p); err != nil {
+		return "", err
+	}
+	administrators := helpers.CreateUserArray(p.Administrators)
+	members := helpers.CreateUserArray(p.Members)
+	viewers := helpers.CreateUserArray(p.Viewers)
+
+	projects, err := cloudassembly.GetProject(APIClient, p.Name, "")
+	if err != nil {
+		return "", err
+	}
+	if len(projects) > 0 {
+		_, err = cloudassembly.UpdateProject(APIClient, *projects[0].ID, p.Name, p.Description, administrators, members, viewers, nil, nil, p.OperationTimeout, p.MachineNamingTemplate, &p.SharedResources)
+		return "updated", err
+	}
+	_, err = cloudassembly.CreateProject(APIClient, p.Name, p.Description, administrators, members, viewers, nil, nil, p.OperationTimeout, p.MachineNamingTemplate, &p.SharedResources)
+	return "created", err
+}
+
+// applyVariable creates or updates a Code Stream Variable
+func applyVariable(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	var v types.VariableRequest
+	if err := document.Decode(&v); err != nil {
+		return "", err
+	}
+	variables, err := codestream.GetVariable(APIClient, "", v.Name, v.Project, "")
+	if err != nil {
+		return "", err
+	}
+	if len(variables) > 0 {
+		_, err = codestream.UpdateVariable(APIClient, variables[0].ID, "", v.Description, v.Type, v.Value)
+		return "updated", err
+	}
+	_, err = codestream.CreateVariable(APIClient, v.Name, v.Description, v.Type, v.Project, v.Value)
+	return "created", err
+}
+
+// applyCodeStreamYaml creates or updates a Code Stream Pipeline or Endpoint using the Code Stream import API
+func applyCodeStreamYaml(APIClient *types.APIClientOptions, document *manifest.Document, exists bool, importType string) (string, error) {
+	if exists {
+		return "updated", codestream.ImportYamlContent(APIClient, document.Raw, "apply", "", importType)
+	}
+	return "created", codestream.ImportYamlContent(APIClient, document.Raw, "create", "", importType)
+}
+
+// applyCustomIntegration creates or updates a Code Stream Custom Integration
+func applyCustomIntegration(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	var ci types.CustomIntegration
+	if err := document.Decode(&ci); err != nil {
+		return "", err
+	}
+	customIntegrations, err := codestream.GetCustomIntegration(APIClient, "", ci.Name)
+	if err != nil {
+		return "", err
+	}
+	if len(customIntegrations) > 0 {
+		_, err = codestream.UpdateCustomIntegration(APIClient, customIntegrations[0].ID, ci.Description, ci.Yaml, "", "")
+		return "updated", err
+	}
+	_, err = codestream.CreateCustomIntegration(APIClient, ci.Name, ci.Description, ci.Yaml, "")
+	return "created", err
+}
+
+// applyCloudTemplate creates or updates a Cloud Assembly Cloud Template. The content
+// can be set inline, or read from a file referenced by the file field.
+func applyCloudTemplate(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	var ct cloudTemplateManifest
+	if err := document.Decode(&ct); err != nil {
+		return "", err
+	}
+	if file := document.File("file"); file != "" {
+		if err := integrity.Check(APIClient.Integrity, file); err != nil {
+			return "", err
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		ct.Content = string(content)
+	}
+	projectID, err := cloudassembly.GetProjectID(APIClient, ct.Project)
+	if err != nil {
+		return "", err
+	}
+	templates, err := cloudassembly.GetCloudTemplate(APIClient, "", ct.Name, ct.Project)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range templates {
+		if t.Name == ct.Name {
+			_, err = cloudassembly.UpdateCloudTemplate(APIClient, t.ID, ct.Name, ct.Description, projectID, ct.Content, ct.RequestScopeOrg)
+			return "updated", err
+		}
+	}
+	_, err = cloudassembly.CreateCloudTemplate(APIClient, ct.Name, ct.Description, projectID, ct.Content, ct.RequestScopeOrg)
+	return "created", err
+}
+
+// applyPropertyGroup creates or updates a Cloud Assembly Property Group
+func applyPropertyGroup(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+	var pg models.PropertyGroup
+	if err := document.Decode(&pg); err != nil {
+		return "", err
+	}
+	if document.Project != "" {
+		projectID, err := cloudassembly.GetProjectID(APIClient, document.Project)
+		if err != nil {
+			return "", err
+		}
+		pg.ProjectID = projectID
+	}
+	propertyGroups, err := cloudassembly.GetPropertyGroups(APIClient, "", pg.Name, document.Project)
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range propertyGroups {
+		if existing.Name == pg.Name {
+			_, err = cloudassembly.UpdatePropertyGroup(APIClient, existing.ID, &pg)
+			return "updated", err
+		}
+	}
+	_, err = cloudassembly.CreatePropertyGroup(APIClient, &pg)
+	return "created", err
+}
+
+// applyOrchestratorFile imports an exported Workflow, Action or Package, overwriting any existing version when --force is set
+func applyOrchestratorFile(APIClient *types.APIClientOptions, document *manifest.Document) error {
+	file := document.File("file")
+	if file == "" {
+		return errors.New(document.Kind + " manifests must reference an exported file")
+	}
+
+	switch document.Kind {
+	case manifest.KindWorkflow:
+		categoryID, err := orchestrator.GetCategoryID(APIClient, document.Field("category"), "WorkflowCategory")
+		if err != nil {
+			return err
+		}
+		return orchestrator.ImportWorkflow(APIClient, file, categoryID)
+	case manifest.KindAction:
+		category := document.Field("category")
+		if category == "" {
+			category = document.Field("module")
+		}
+		return orchestrator.ImportAction(APIClient, file, category)
+	}
+	tagImportMode := document.Field("tagImportMode")
+	if tagImportMode == "" {
+		tagImportMode = "ImportButPreserveExistingValue"
+	}
+	options := types.ImportPackageOptions{
+		ImportConfigurationAttributeValues:      !strings.EqualFold(document.Field("importConfigurationAttributeValues"), "false"),
+		ImportConfigSecureStringAttributeValues: strings.EqualFold(document.Field("importConfigSecureStringAttributeValues"), "true"),
+		TagImportMode:                           tagImportMode,
+	}
+	if err := checkPackageConflict(APIClient, file, options); err != nil {
+		return err
+	}
+	return orchestrator.CreatePackage(APIClient, file, options)
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&manifestPath, "filename", "f", "", "Manifest file, or directory of manifests, to apply")
+	applyCmd.MarkFlagRequired("filename")
+}
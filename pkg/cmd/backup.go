@@ -0,0 +1,486 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/cloudassembly"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
+	"github.com/sammcgeown/vra-cli/pkg/util/backup"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+var (
+	backupCustomIntegrations bool
+	backupPackages           []string
+	backupWorkflows          []string
+	backupActions            []string
+	restoreProject           string
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up everything in a Project",
+	Long: `Export the Project, and its Code Stream Pipelines, Endpoints and Variables and Cloud Assembly Cloud Templates
+and Property Groups, to one directory or .tar.gz archive. Custom Integrations, which are not part of a Project,
+and vRO Workflows, Actions and Packages can be included too.
+
+The backup has a manifest.json listing every object, and the objects are written as manifests that restore
+(or apply) can read. The values of SECRET and RESTRICTED Variables are not returned by the API, so they are
+not backed up.
+
+# Back up a Project to a directory
+vra-cli backup --project "Field Demo" --exportPath ./field-demo
+
+# Back up a Project, its Custom Integrations and a vRO Package to an archive
+vra-cli backup --project "Field Demo" --customIntegrations --package com.example.demo --exportPath field-demo.tar.gz`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := exportPath
+		if backup.IsArchive(exportPath) {
+			tempDir, err := ioutil.TempDir("", "vra-cli-backup")
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer os.RemoveAll(tempDir)
+			dir = tempDir
+		} else if entries, err := ioutil.ReadDir(dir); err == nil && len(entries) > 0 && !APIClient.Force {
+			log.Fatalln(dir, "is not empty, use --force to write to it anyway")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Fatalln(err)
+		}
+
+		m := &backup.Manifest{
+			FormatVersion: backup.FormatVersion,
+			Version:       version,
+			Target:        targetConfig.Name,
+			Server:        targetConfig.Server,
+			Project:       projectName,
+			Created:       time.Now().UTC(),
+		}
+		if err := backupProject(APIClient, dir, m, projectName); err != nil {
+			log.Fatalln("Unable to back up", projectName+":", err)
+		}
+		if err := backupShared(APIClient, dir, m); err != nil {
+			log.Fatalln("Unable to back up", projectName+":", err)
+		}
+		if err := backup.WriteManifest(dir, m); err != nil {
+			log.Fatalln("Unable to write the backup manifest:", err)
+		}
+		if err := integrity.Record(filepath.Join(dir, backup.ManifestFile), APIClient.SigningKey); err != nil {
+			log.Fatalln("Unable to record the checksum of the backup manifest:", err)
+		}
+		if dir != exportPath {
+			if err := backup.Archive(dir, exportPath); err != nil {
+				log.Fatalln("Unable to write", exportPath+":", err)
+			}
+		}
+		printBackupManifest(m)
+		log.Infoln("Backed up", len(m.Objects), "objects to", exportPath)
+	},
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a Project backup",
+	Long: `Restore a backup written by vra-cli backup, creating objects that do not exist and updating those that do.
+Use --toProject to restore into a new Project, and config use-target to restore to another vRA.
+
+# Restore a backup
+vra-cli restore --importPath field-demo.tar.gz
+
+# Restore a backup as a copy of the Project
+vra-cli restore --importPath ./field-demo --toProject "Field Demo Copy"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := importPath
+		if backup.IsArchive(importPath) {
+			tempDir, err := ioutil.TempDir("", "vra-cli-restore")
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer os.RemoveAll(tempDir)
+			if err := backup.Extract(importPath, tempDir); err != nil {
+				log.Fatalln("Unable to extract", importPath+":", err)
+			}
+			dir = tempDir
+		}
+		if err := integrity.Check(APIClient.Integrity, filepath.Join(dir, backup.ManifestFile)); err != nil {
+			log.Fatalln("Unable to restore the backup:", err)
+		}
+		m, err := backup.ReadManifest(dir)
+		if err != nil {
+			log.Fatalln("Unable to read the backup manifest:", err)
+		}
+		log.Infoln("Restoring the backup of", m.Project, "from", m.Target, "taken", m.Created.Local().Format("2006-01-02 15:04:05"))
+
+		documents, err := loadBackup(dir, m, restoreProject)
+		if err != nil {
+			log.Fatalln("Unable to read the backup:", err)
+		}
+		applyDocuments(documents, m.Skipped)
+	},
+}
+
+// loadBackup reads the manifest documents in a backup, moving them to another Project if one is given
+func loadBackup(dir string, m *backup.Manifest, project string) ([]*manifest.Document, error) {
+	var documents []*manifest.Document
+	for _, file := range m.Files() {
+		path, err := backup.Path(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		fileDocuments, err := manifest.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range fileDocuments {
+			// Files referenced by a document are uploaded with it, so must be in the backup too
+			if file := document.File("file"); file != "" && !backup.Within(dir, file) {
+				return nil, fmt.Errorf("invalid path in backup: %s references %s", document, file)
+			}
+			if project != "" {
+				if err := document.SetProject(project); err != nil {
+					return nil, fmt.Errorf("unable to move %s to %s: %w", document, project, err)
+				}
+			}
+		}
+		documents = append(documents, fileDocuments...)
+	}
+	for i := range m.Skipped {
+		if project != "" && m.Skipped[i].Project != "" {
+			m.Skipped[i].Project = project
+		}
+	}
+	return documents, nil
+}
+
+// backupProject writes the objects in a Project to dir, adding them to the manifest
+func backupProject(APIClient *types.APIClientOptions, dir string, m *backup.Manifest, projectName string) error {
+	// Cloud Assembly
+	projects, err := cloudassembly.GetProject(APIClient, projectName, "")
+	if err != nil {
+		return err
+	}
+	var project *projectManifest
+	for _, p := range projects {
+		if p.Name == projectName {
+			project = &projectManifest{
+				Name:                  p.Name,
+				Description:           p.Description,
+				Administrators:        userEmails(p.Administrators),
+				Members:               userEmails(p.Members),
+				Viewers:               userEmails(p.Viewers),
+				OperationTimeout:      p.OperationTimeout,
+				MachineNamingTemplate: p.MachineNamingTemplate,
+				SharedResources:       p.SharedResources,
+			}
+			if err := writeBackupDocument(dir, m, manifest.KindProject, *p.ID, "project.json", project); err != nil {
+				return err
+			}
+		}
+	}
+	if project == nil {
+		return errors.New("Project not found")
+	}
+
+	cloudTemplates, err := cloudassembly.GetCloudTemplate(APIClient, "", "", projectName)
+	if err != nil {
+		return err
+	}
+	for _, t := range cloudTemplates {
+		contentFile := backup.FileName(t.Name) + ".yaml"
+		if err := writeBackupFile(dir, filepath.Join("cloudtemplates", contentFile), []byte(t.Content)); err != nil {
+			return err
+		}
+		document := map[string]interface{}{
+			"name":            t.Name,
+			"description":     t.Description,
+			"project":         projectName,
+			"requestScopeOrg": t.RequestScopeOrg,
+			"file":            contentFile,
+		}
+		if err := writeBackupDocument(dir, m, manifest.KindCloudTemplate, t.ID, "cloudtemplates/"+backup.FileName(t.Name)+".json", document); err != nil {
+			return err
+		}
+	}
+
+	propertyGroups, err := cloudassembly.GetPropertyGroups(APIClient, "", "", projectName)
+	if err != nil {
+		return err
+	}
+	for _, pg := range propertyGroups {
+		document, err := backupFields(pg, "id", "orgId", "projectId", "projectName", "createdAt", "createdBy", "updatedAt", "updatedBy")
+		if err != nil {
+			return err
+		}
+		document["project"] = projectName
+		if err := writeBackupDocument(dir, m, manifest.KindPropertyGroup, pg.ID, "propertygroups/"+backup.FileName(pg.Name)+".json", document); err != nil {
+			return err
+		}
+	}
+
+	// Code Stream
+	variables, err := codestream.GetVariable(APIClient, "", "", projectName, "")
+	if err != nil {
+		return err
+	}
+	for _, v := range variables {
+		if v.Type == "SECRET" || v.Type == "RESTRICTED" {
+			log.Warnln("The value of", v.Type, "Variable", v.Name, "is not returned by the API, and is not backed up")
+			m.Skipped = append(m.Skipped, backup.Object{Kind: manifest.KindVariable, ID: v.ID, Name: v.Name, Project: projectName, Reason: v.Type + " value is not returned by the API"})
+			continue
+		}
+		document := types.VariableRequest{Project: v.Project, Name: v.Name, Description: v.Description, Type: v.Type, Value: v.Value}
+		if err := writeBackupDocument(dir, m, manifest.KindVariable, v.ID, "variables/"+backup.FileName(v.Name)+".json", document); err != nil {
+			return err
+		}
+	}
+
+	endpoints, err := codestream.GetEndpoint(APIClient, "", "", projectName, "", "")
+	if err != nil {
+		return err
+	}
+	for _, e := range endpoints {
+		if err := backupCodeStreamYaml(APIClient, dir, m, projectName, manifest.KindEndpoint, e.ID, e.Name, "endpoint"); err != nil {
+			return err
+		}
+	}
+
+	pipelines, err := codestream.GetPipeline(APIClient, "", "", projectName, "")
+	if err != nil {
+		return err
+	}
+	for _, p := range pipelines {
+		if err := backupCodeStreamYaml(APIClient, dir, m, projectName, manifest.KindPipeline, p.ID, p.Name, "pipeline"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupShared writes the Custom Integrations and vRO content, which do not belong to a Project, to dir
+func backupShared(APIClient *types.APIClientOptions, dir string, m *backup.Manifest) error {
+	if backupCustomIntegrations {
+		customIntegrations, err := codestream.GetCustomIntegration(APIClient, "", "")
+		if err != nil {
+			return err
+		}
+		for _, ci := range customIntegrations {
+			document := map[string]interface{}{"name": ci.Name, "description": ci.Description, "yaml": ci.Yaml}
+			if err := writeBackupDocument(dir, m, manifest.KindCustomIntegration, ci.ID, "customintegrations/"+backup.FileName(ci.Name)+".json", document); err != nil {
+				return err
+			}
+		}
+	}
+
+	// vRealize Orchestrator
+	for _, name := range backupWorkflows {
+		workflows, err := orchestrator.GetWorkflow(APIClient, "", "", name)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, w := range workflows {
+			if w.Name != name {
+				continue
+			}
+			found = true
+			category, err := orchestrator.GetCategoryByID(APIClient, w.CategoryID)
+			if err != nil {
+				return err
+			}
+			if err := orchestrator.ExportWorkflow(APIClient, w.ID, backup.FileName(w.Name), filepath.Join(dir, "workflows")); err != nil {
+				return err
+			}
+			document := map[string]interface{}{"name": w.Name, "file": backup.FileName(w.Name) + ".zip", "category": category.Path}
+			if err := writeBackupDocument(dir, m, manifest.KindWorkflow, w.ID, "workflows/"+backup.FileName(w.Name)+".json", document); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return errors.New("Workflow " + name + " not found")
+		}
+	}
+	for _, name := range backupActions {
+		actions, err := orchestrator.GetAction(APIClient, "", "", name)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, a := range actions {
+			if a.Name != name && a.Fqn != name {
+				continue
+			}
+			found = true
+			fileName := backup.FileName(a.Module + "." + a.Name)
+			if err := orchestrator.ExportAction(APIClient, a.ID, fileName, filepath.Join(dir, "actions")); err != nil {
+				return err
+			}
+			document := map[string]interface{}{"name": a.Name, "file": fileName + ".action", "module": a.Module}
+			if err := writeBackupDocument(dir, m, manifest.KindAction, a.ID, "actions/"+fileName+".json", document); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return errors.New("Action " + name + " not found")
+		}
+	}
+	for _, name := range backupPackages {
+		packages, err := orchestrator.GetPackage(APIClient, name)
+		if err != nil {
+			return err
+		}
+		packageDir := filepath.Join(dir, "packages")
+		if err := os.MkdirAll(packageDir, 0755); err != nil {
+			return err
+		}
+		if err := orchestrator.ExportPackage(APIClient, name, types.ExportPackageOptions{
+			ExportConfigurationAttributeValues: true,
+			ExportGlobalTags:                   true,
+			ViewContents:                       true,
+			AddToPackage:                       true,
+			EditContents:                       true,
+		}, packageDir); err != nil {
+			return err
+		}
+		document := map[string]interface{}{"name": name, "file": name + ".package"}
+		if err := writeBackupDocument(dir, m, manifest.KindPackage, packages[0].ID, "packages/"+backup.FileName(name)+".json", document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupCodeStreamYaml writes the Code Stream export of a Pipeline or Endpoint
+func backupCodeStreamYaml(APIClient *types.APIClientOptions, dir string, m *backup.Manifest, projectName, kind, id, name, object string) error {
+	content, err := codestream.GetExportYaml(APIClient, name, projectName, object)
+	if err != nil {
+		return err
+	}
+	file := object + "s/" + backup.FileName(name) + ".yaml"
+	if err := writeBackupFile(dir, file, content); err != nil {
+		return err
+	}
+	m.Objects = append(m.Objects, backup.Object{Kind: kind, ID: id, Name: name, Project: projectName, File: file})
+	return nil
+}
+
+// writeBackupDocument writes an object as a manifest document, and adds it to the backup manifest
+func writeBackupDocument(dir string, m *backup.Manifest, kind, id, file string, object interface{}) error {
+	document, err := backupFields(object)
+	if err != nil {
+		return err
+	}
+	document["kind"] = kind
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeBackupFile(dir, file, content); err != nil {
+		return err
+	}
+	name, _ := document["name"].(string)
+	project, _ := document["project"].(string)
+	m.Objects = append(m.Objects, backup.Object{Kind: kind, ID: id, Name: name, Project: project, File: file})
+	return nil
+}
+
+// writeBackupFile writes a file in the backup directory
+func writeBackupFile(dir, file string, content []byte) error {
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	return integrity.Record(path, APIClient.SigningKey)
+}
+
+// backupFields converts an object to a map of its JSON fields, without the ignored fields
+func backupFields(object interface{}, ignore ...string) (map[string]interface{}, error) {
+	content, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range ignore {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+// userEmails returns the email addresses of the users
+func userEmails(users []*models.User) []string {
+	emails := []string{}
+	for _, user := range users {
+		if user.Email != nil {
+			emails = append(emails, *user.Email)
+		}
+	}
+	return emails
+}
+
+// printBackupManifest prints the number of objects of each kind in the backup
+func printBackupManifest(m *backup.Manifest) {
+	if APIClient.Output == "json" {
+		helpers.PrettyPrint(m)
+		return
+	}
+	kinds := m.Kinds()
+	var names []string
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	sort.Strings(names)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Objects"})
+	for _, kind := range names {
+		table.Append([]string{kind, strconv.Itoa(kinds[kind])})
+	}
+	table.Render()
+}
+
+func init() {
+	backupCmd.Flags().StringVarP(&projectName, "project", "p", "", "Project to back up")
+	backupCmd.Flags().StringVar(&exportPath, "exportPath", "", "Directory, or .tar.gz archive, to write the backup to")
+	backupCmd.Flags().BoolVar(&backupCustomIntegrations, "customIntegrations", false, "Include all Custom Integrations")
+	backupCmd.Flags().StringSliceVar(&backupPackages, "package", []string{}, "vRO Package to include (can be repeated)")
+	backupCmd.Flags().StringSliceVar(&backupWorkflows, "workflow", []string{}, "vRO Workflow to include (can be repeated)")
+	backupCmd.Flags().StringSliceVar(&backupActions, "action", []string{}, "vRO Action to include, by name or module/name (can be repeated)")
+	backupCmd.MarkFlagRequired("project")
+	backupCmd.MarkFlagRequired("exportPath")
+
+	restoreCmd.Flags().StringVar(&importPath, "importPath", "", "Backup directory, or .tar.gz archive, to restore")
+	restoreCmd.Flags().StringVar(&restoreProject, "toProject", "", "Restore into this Project instead of the original")
+	restoreCmd.MarkFlagRequired("importPath")
+
+	registerCompletions(backupCmd, "")
+}
@@ -0,0 +1,62 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/backup"
+)
+
+func TestLoadBackupPaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "backup")
+	for file, content := range map[string]string{
+		"outside.yaml":                   "kind: Variable\nproject: Field Demo\nname: region\nvalue: eu-west-1\n",
+		"backup/variables/region.yaml":   "kind: Variable\nproject: Field Demo\nname: region\nvalue: eu-west-1\n",
+		"backup/workflows/deploy.yaml":   "kind: Workflow\nname: Deploy\ncategory: Demo\nfile: deploy.workflow\n",
+		"backup/workflows/escape.yaml":   "kind: Workflow\nname: Escape\ncategory: Demo\nfile: ../../outside.yaml\n",
+		"backup/workflows/absolute.yaml": "kind: Workflow\nname: Absolute\ncategory: Demo\nfile: /etc/passwd\n",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		file string
+		err  string
+	}{
+		{name: "variable", file: "variables/region.yaml"},
+		{name: "workflow", file: "workflows/deploy.yaml"},
+		{name: "manifest outside the backup", file: "../outside.yaml", err: "invalid path in backup: ../outside.yaml"},
+		{name: "file outside the backup", file: "workflows/escape.yaml", err: "invalid path in backup"},
+		{name: "absolute file", file: "workflows/absolute.yaml", err: "invalid path in backup"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &backup.Manifest{Objects: []backup.Object{{Kind: "Variable", Name: tt.name, File: tt.file}}}
+			documents, err := loadBackup(dir, m, "")
+			if tt.err == "" {
+				if err != nil || len(documents) != 1 {
+					t.Errorf("loadBackup(%s) = %d documents, %v, want 1 document", tt.file, len(documents), err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("loadBackup(%s) error = %v, want %q", tt.file, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// workerClients returns an API client for each worker of a batch of count items
+func workerClients(count int) []*types.APIClientOptions {
+	return auth.Clients(APIClient, count)
+}
+
+// exportObjects exports the named objects concurrently, printing the outcome for each object and
+// logging the number of objects that could not be exported
+func exportObjects(names []string, objects string, export func(APIClient *types.APIClientOptions, i int) error) {
+	clients := workerClients(len(names))
+	results := batch.Run("Exporting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+		if err := export(clients[worker], i); err != nil {
+			return "", err
+		}
+		return "exported", nil
+	})
+	batch.Print(os.Stdout, results, APIClient.Output)
+	if failed := batch.Failures(results); failed > 0 {
+		log.Errorln(failed, "of", len(results), objects, "could not be exported")
+	}
+}
+
+// reportDeleted prints the outcome of a bulk delete, and logs the number of objects that could not be deleted
+func reportDeleted(results []batch.Result, objects string) {
+	if len(results) == 0 {
+		log.Infoln("No", objects, "deleted")
+		return
+	}
+	batch.Print(os.Stdout, results, APIClient.Output)
+	if failed := batch.Failures(results); failed > 0 {
+		log.Errorln(failed, "of", len(results), objects, "could not be deleted")
+	}
+}
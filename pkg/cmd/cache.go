@@ -0,0 +1,57 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var clearAllTargets bool
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local name to ID cache",
+	Long: `Manage the local cache used to resolve Project, Category and Endpoint names to IDs.
+
+Cached lookups expire after --cacheTTL, use --no-cache to bypass the cache for a single command.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  func(cmd *cobra.Command, args []string) {},
+}
+
+// clearCacheCmd represents the cache clear command
+var clearCacheCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the local cache",
+	Long: `Clear the local cache for the current target, or for all targets
+
+# Clear the cache for the current target
+vra-cli cache clear
+
+# Clear the cache for all targets
+vra-cli cache clear --all`,
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		target := ""
+		if !clearAllTargets {
+			if !shellSession { // The cache is local, so the target is read but not authenticated
+				loadTarget()
+			}
+			target = targetConfig.Name
+		}
+		removed, err := cache.Clear(target)
+		if err != nil {
+			log.Fatalln("Unable to clear cache:", err)
+		}
+		log.Infoln("Cleared", len(removed), "cache file(s)")
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(clearCacheCmd)
+	clearCacheCmd.Flags().BoolVar(&clearAllTargets, "all", false, "Clear the cache for all targets")
+}
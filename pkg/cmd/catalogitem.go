@@ -233,5 +233,6 @@ func init() {
 	// deleteCatalogItemCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the CatalogItem to delete")
 	// deleteCatalogItemCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the CatalogItem to delete")
 	// deleteCatalogItemCmd.Flags().StringVarP(&project, "project", "p", "", "Delete CatalogItems by Project")
-
+	// Completions
+	registerCompletions(getCatalogItemCmd, "")
 }
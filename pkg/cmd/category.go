@@ -29,6 +29,8 @@ var getCategoryCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
 		var categories []*types.WsCategory
+		// The table only needs the fields returned in the category list
+		APIClient.Summary = APIClient.Output == "table"
 		if name != "" {
 			categories, err = orchestrator.GetCategoryByName(APIClient, name, categoryType)
 		} else if id != "" {
@@ -173,4 +175,8 @@ func init() {
 	updateCategoryCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the Category")
 	updateCategoryCmd.Flags().StringVarP(&name, "name", "n", "", "Category Name")
 	updateCategoryCmd.Flags().StringVar(&parentCategoryID, "parent", "", "Category Category ID")
+	// Completions
+	registerCompletions(getCategoryCmd, "category")
+	registerCompletions(delCategoryCmd, "category")
+	registerCompletions(updateCategoryCmd, "category")
 }
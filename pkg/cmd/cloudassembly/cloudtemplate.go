@@ -5,11 +5,13 @@ SPDX-License-Identifier: BSD-2-Clause
 package cloudassembly
 
 import (
-	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/sammcgeown/vra-cli/pkg/util/canonical"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/vmware/vra-sdk-go/pkg/client/blueprint"
@@ -27,13 +29,11 @@ func GetCloudTemplate(APIClient *types.APIClientOptions, id string, name string,
 			CloudTemplateParams.Name = &name
 		}
 		if project != "" {
-			p, perr := GetProject(APIClient, project, "")
+			projectID, perr := GetProjectID(APIClient, project)
 			if perr != nil {
 				return nil, perr
-			} else if len(p) == 0 {
-				return nil, errors.New("Project not found")
 			}
-			CloudTemplateParams.Projects = []string{*(p[0]).ID}
+			CloudTemplateParams.Projects = []string{projectID}
 		}
 
 		log.Debug(CloudTemplateParams)
@@ -118,8 +118,26 @@ func CreateCloudTemplate(APIClient *types.APIClientOptions, name string, descrip
 	return ret.Payload, err
 }
 
+// UpdateCloudTemplate - Update an existing Cloud Assembly Cloud Template
+func UpdateCloudTemplate(APIClient *types.APIClientOptions, id string, name string, description string, projectID string, content string, scope bool) (*models.Blueprint, error) {
+	UpdateParams := blueprint.NewUpdateBlueprintUsingPUT1Params()
+	UpdateParams.BlueprintID = strfmt.UUID(id)
+	UpdateParams.Blueprint = &models.Blueprint{
+		Name:            name,
+		Description:     description,
+		ProjectID:       projectID,
+		Content:         content,
+		RequestScopeOrg: scope,
+	}
+	ret, err := APIClient.SDKClient.Blueprint.UpdateBlueprintUsingPUT1(UpdateParams)
+	if err != nil {
+		return nil, err
+	}
+	return ret.Payload, err
+}
+
 // ExportCloudTemplate - Export a Cloud Assembly Cloud Template
-func ExportCloudTemplate(name, project, content, path string) error {
+func ExportCloudTemplate(APIClient *types.APIClientOptions, name, project, content, path string) error {
 	var exportPath string
 	if path != "" {
 		exportPath = path
@@ -135,15 +153,15 @@ func ExportCloudTemplate(name, project, content, path string) error {
 		// If path is not specified, use the current path
 		exportPath, _ = os.Getwd()
 	}
-	exportPath = filepath.Join(exportPath, project+" - "+name+".yaml")
-	f, cerr := os.Create(exportPath) // Open the file for writing
-	if cerr != nil {
-		return cerr
+	if APIClient.Canonical {
+		// The content is stored as it was written, so only the file name and line endings are normalised
+		exportPath = filepath.Join(exportPath, canonical.FileName(project+" - "+name)+".yaml")
+		content = strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n") + "\n"
+	} else {
+		exportPath = filepath.Join(exportPath, project+" - "+name+".yaml")
 	}
-	defer f.Close() // Defer closing until just before this function returns
-	_, werr := f.WriteString(content)
-	if werr != nil {
+	if werr := os.WriteFile(exportPath, []byte(content), 0644); werr != nil {
 		return werr
 	}
-	return nil
+	return integrity.Record(exportPath, APIClient.SigningKey)
 }
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/vmware/vra-sdk-go/pkg/client/project"
@@ -46,8 +47,26 @@ func GetProject(APIClient *types.APIClientOptions, name string, id string) ([]*m
 	return ret.Payload.Content, nil
 }
 
+// GetProjectID - Resolve a Project name to its ID, using the local cache
+func GetProjectID(APIClient *types.APIClientOptions, name string) (string, error) {
+	var id string
+	if cache.Get(APIClient, "project:"+name, &id) {
+		return id, nil
+	}
+	p, err := GetProject(APIClient, name, "")
+	if err != nil {
+		return "", err
+	} else if len(p) == 0 {
+		return "", errors.New("Project not found")
+	}
+	id = *p[0].ID
+	cache.Set(APIClient, "project:"+name, id)
+	return id, nil
+}
+
 // DeleteProject - Delete Project
 func DeleteProject(APIClient *types.APIClientOptions, id string) error {
+	defer cache.Invalidate(APIClient, "project:")
 
 	// Workaround an issue where the cloud regions need to be removed before the project can be deleted.
 	_, err := APIClient.SDKClient.Project.UpdateProject(project.NewUpdateProjectParams().WithAPIVersion(&APIClient.Version).WithID(id).WithBody(&models.IaaSProjectSpecification{
@@ -123,5 +142,8 @@ func UpdateProject(APIClient *types.APIClientOptions, id string, name string, de
 	if err != nil {
 		return nil, err
 	}
+	if name != "" { // The project may have been renamed
+		cache.Invalidate(APIClient, "project:")
+	}
 	return updatedProject.Payload, nil
 }
@@ -5,8 +5,6 @@ SPDX-License-Identifier: BSD-2-Clause
 package cloudassembly
 
 import (
-	"errors"
-
 	"github.com/go-openapi/strfmt"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	"github.com/vmware/vra-sdk-go/pkg/client/property_groups"
@@ -35,13 +33,11 @@ func GetPropertyGroups(APIClient *types.APIClientOptions, id string, name string
 		PropertyGroupsParams.SetName(&name)
 	}
 	if project != "" {
-		p, perr := GetProject(APIClient, project, "")
+		projectID, perr := GetProjectID(APIClient, project)
 		if perr != nil {
 			return nil, perr
-		} else if len(p) == 0 {
-			return nil, errors.New("Project not found")
 		}
-		PropertyGroupsParams.SetProjects([]string{*(p[0]).ID})
+		PropertyGroupsParams.SetProjects([]string{projectID})
 	}
 
 	PropertyGroups, err := APIClient.SDKClient.PropertyGroups.ListPropertyGroupsUsingGET(PropertyGroupsParams)
@@ -50,3 +46,37 @@ func GetPropertyGroups(APIClient *types.APIClientOptions, id string, name string
 	}
 	return PropertyGroups.Payload.Content, nil
 }
+
+// CreatePropertyGroup creates a Property Group
+func CreatePropertyGroup(APIClient *types.APIClientOptions, propertyGroup *models.PropertyGroup) (*models.PropertyGroup, error) {
+	PropertyGroupParams := property_groups.NewCreatePropertyGroupUsingPOSTParams().
+		WithAPIVersion(&APIClient.Version).
+		WithPropertyGroup(propertyGroup)
+	PropertyGroup, err := APIClient.SDKClient.PropertyGroups.CreatePropertyGroupUsingPOST(PropertyGroupParams)
+	if err != nil {
+		return nil, err
+	}
+	return PropertyGroup.Payload, nil
+}
+
+// UpdatePropertyGroup updates a Property Group
+func UpdatePropertyGroup(APIClient *types.APIClientOptions, id string, propertyGroup *models.PropertyGroup) (*models.PropertyGroup, error) {
+	PropertyGroupParams := property_groups.NewUpdatePropertyGroupUsingPUTParams().
+		WithAPIVersion(&APIClient.Version).
+		WithPropertyGroupID(strfmt.UUID(id)).
+		WithPropertyGroup(propertyGroup)
+	PropertyGroup, err := APIClient.SDKClient.PropertyGroups.UpdatePropertyGroupUsingPUT(PropertyGroupParams)
+	if err != nil {
+		return nil, err
+	}
+	return PropertyGroup.Payload, nil
+}
+
+// DeletePropertyGroup deletes a Property Group
+func DeletePropertyGroup(APIClient *types.APIClientOptions, id string) error {
+	PropertyGroupParams := property_groups.NewDeletePropertyGroupUsingDELETEParams().
+		WithAPIVersion(&APIClient.Version).
+		WithPropertyGroupID(strfmt.UUID(id))
+	_, err := APIClient.SDKClient.PropertyGroups.DeletePropertyGroupUsingDELETE(PropertyGroupParams)
+	return err
+}
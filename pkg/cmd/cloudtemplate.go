@@ -8,10 +8,13 @@ import (
 	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/sammcgeown/vra-cli/pkg/cmd/cloudassembly"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
@@ -38,6 +41,14 @@ var getCloudTemplateCmd = &cobra.Command{
 		if resultCount == 0 {
 			// No results
 			log.Infoln("No results found")
+		} else if APIClient.Output == "export" || exportPath != "" {
+			names := make([]string, len(response))
+			for i, c := range response {
+				names[i] = c.Name
+			}
+			exportObjects(names, "Cloud Templates", func(client *types.APIClientOptions, i int) error {
+				return cloudassembly.ExportCloudTemplate(client, response[i].Name, response[i].ProjectName, response[i].Content, exportPath)
+			})
 		} else if resultCount == 1 {
 			if schema {
 				// if inputSchema, err := getCloudTemplateInputSchema(response[0].ID); err != nil {
@@ -153,17 +164,13 @@ var createCloudTemplateCmd = &cobra.Command{
 		// If project name flag is set, get the project ID and update the request
 		if projectName != "" {
 			log.Debugln("Project: " + projectName)
-			projectObjs, pErr := cloudassembly.GetProject(APIClient, projectName, "")
+			var pErr error
+			projectID, pErr = cloudassembly.GetProjectID(APIClient, projectName)
 			if pErr != nil {
-				log.Fatalln(pErr)
-			} else if len(projectObjs) == 1 {
-				projectObj := projectObjs[0]
-				projectID = *projectObj.ID
-				log.Debugln("Project ID: " + projectID)
-				cloudTemplateReq.ProjectID = projectID
-			} else {
-				log.Fatalln("Unable to find Project \"" + projectName + "\"")
+				log.Fatalln("Unable to find Project \""+projectName+"\":", pErr)
 			}
+			log.Debugln("Project ID: " + projectID)
+			cloudTemplateReq.ProjectID = projectID
 		}
 		// If name flag is set, update the request
 		if name != "" {
@@ -183,11 +190,36 @@ var createCloudTemplateCmd = &cobra.Command{
 		} else if scope == "project" {
 			cloudTemplateReq.RequestScopeOrg = false
 		}
-		// Create the cloud template
-		cloudTemplate, err := cloudassembly.CreateCloudTemplate(APIClient, cloudTemplateReq.Name, cloudTemplateReq.Description, cloudTemplateReq.ProjectID, cloudTemplateReq.Content, cloudTemplateReq.RequestScopeOrg)
+		// Resolve the project name of piped JSON, which only has the project ID
+		if projectName == "" && cloudTemplateReq.ProjectID != "" {
+			projects, err := cloudassembly.GetProject(APIClient, "", cloudTemplateReq.ProjectID)
+			if err != nil || len(projects) == 0 {
+				log.Fatalln("Unable to find Project \""+cloudTemplateReq.ProjectID+"\":", err)
+			}
+			projectName = projects[0].Name
+		}
+		// Create the cloud template, resolving any conflict with an existing template
+		document, err := manifest.New(manifest.KindCloudTemplate, "", cloudTemplateManifest{
+			Name:            cloudTemplateReq.Name,
+			Description:     cloudTemplateReq.Description,
+			Project:         projectName,
+			Content:         cloudTemplateReq.Content,
+			RequestScopeOrg: cloudTemplateReq.RequestScopeOrg,
+		})
 		if err != nil {
-			log.Errorln("Unable to create Cloud Template(s): ", err)
+			log.Fatalln("Unable to create Cloud Template:", err)
 		}
+		results := importDocuments([]*manifest.Document{document}, onConflict(conflict.Fail))
+		if APIClient.DryRun || !strings.HasPrefix(results[0].Result, "created") && results[0].Result != "updated" {
+			reportImport(results)
+			return
+		}
+		// Print the created or updated cloud template, which may have been renamed
+		cloudTemplates, err := cloudassembly.GetCloudTemplate(APIClient, "", document.Name, projectName)
+		if err != nil || len(cloudTemplates) == 0 {
+			log.Fatalln("Unable to get Cloud Template "+document.Name+":", err)
+		}
+		cloudTemplate := cloudTemplates[0]
 		if APIClient.Output == "json" {
 			helpers.PrettyPrint(cloudTemplate)
 		} else {
@@ -266,5 +298,7 @@ func init() {
 	deleteCloudTemplateCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the Cloud Template to delete")
 	deleteCloudTemplateCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the Cloud Template to delete")
 	deleteCloudTemplateCmd.Flags().StringVarP(&projectName, "project", "p", "", "Project of the Cloud Template to delete")
-
+	// Completions
+	registerCompletions(getCloudTemplateCmd, "cloudtemplate")
+	registerCompletions(deleteCloudTemplateCmd, "cloudtemplate")
 }
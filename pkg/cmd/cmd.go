@@ -9,7 +9,12 @@ import (
 	"os"
 
 	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
 	"github.com/sammcgeown/vra-cli/pkg/util/config"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/dryrun"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
 	types "github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -38,6 +43,8 @@ var (
 	importPath string
 	category   string
 	// force       bool
+	// Tracing
+	traceOptions tracing.Options
 )
 
 // var qParams = map[string]string{
@@ -49,17 +56,29 @@ var rootCmd = &cobra.Command{
 	Use:   "vra-cli",
 	Short: "CLI Interface for VMware vRealize Automation Code Stream",
 	Long:  `Command line interface for VMware vRealize Automation Code Stream`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		tracing.Annotate(cmd.CommandPath(), tracing.CommandKey.String(cmd.CommandPath()))
+		invalidateCompletions(cmd)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if APIClient.DryRun {
+			log.Infoln("[dry-run]", len(dryrun.Mutations()), "changes were not sent to", targetConfig.Server)
+		}
+	},
 }
 
 // Execute is the main process
 func Execute() {
+	defer tracing.Shutdown()
+	addPluginCommands(os.Args[1:])
 	if err := rootCmd.Execute(); err != nil {
 		log.Warnln(err)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(InitConfig)
+	cobra.OnInitialize(InitTracing, InitConfig, applyShellProject)
+	log.RegisterExitHandler(tracing.ExitHandler) // Commands that fail with log.Fatal skip the deferred Shutdown
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.vra-cli.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&APIClient.Debug, "debug", false, "Enable debug logging")
 	rootCmd.PersistentFlags().BoolVar(&APIClient.Confirm, "confirm", false, "Confirm action without prompting for confirmation")
@@ -70,14 +89,60 @@ func init() {
 	// API Paging
 	rootCmd.PersistentFlags().IntVar(&APIClient.Pagination.PageSize, "count", 100, "API Page Size - Count")
 	rootCmd.PersistentFlags().IntVar(&APIClient.Pagination.Skip, "skip", 0, "API Paging - Skip")
+	rootCmd.PersistentFlags().IntVar(&APIClient.Parallel, "parallel", 8, "Maximum number of concurrent API requests")
+	rootCmd.PersistentFlags().BoolVar(&APIClient.DryRun, "dry-run", false, "Print the changes a command would make without making them")
+	rootCmd.PersistentFlags().StringVar(&APIClient.OnConflict, "on-conflict", "", "What to do when an imported object already exists - skip, overwrite, rename or fail (default: fail for create, overwrite for update and apply)")
+	rootCmd.PersistentFlags().BoolVar(&APIClient.Atomic, "atomic", false, "Stop an import at the first failure, delete the objects it created and restore the objects it overwrote")
+	// Integrity
+	rootCmd.PersistentFlags().StringVar(&APIClient.SigningKey, "signingKey", "", "Sign exports with this key (see vra-cli key generate)")
+	rootCmd.PersistentFlags().StringVar(&APIClient.Integrity, "integrity", "none", "Import policy - none, checksum (refuse modified files) or signed (also require a trusted signature)")
+	// Exports
+	rootCmd.PersistentFlags().BoolVar(&APIClient.Canonical, "canonical", false, "Write exports in a stable form for version control - no server generated fields, sorted keys and one file per object")
+	// Cache
+	rootCmd.PersistentFlags().BoolVar(&APIClient.NoCache, "no-cache", false, "Do not use the local name to ID cache")
+	rootCmd.PersistentFlags().DurationVar(&APIClient.CacheTTL, "cacheTTL", cache.DefaultTTL, "How long cached name to ID lookups are valid for")
+	// Tracing
+	rootCmd.PersistentFlags().StringVar(&traceOptions.Endpoint, "traceEndpoint", "", "Export OpenTelemetry spans to this OTLP/HTTP endpoint (host:port)")
+	rootCmd.PersistentFlags().BoolVar(&traceOptions.Insecure, "traceInsecure", false, "Disable TLS when exporting spans to the OTLP endpoint")
+	rootCmd.PersistentFlags().StringVar(&traceOptions.File, "traceFile", "", "Write OpenTelemetry spans as JSON to this file")
 
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(cancelCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(rerunCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(shellCmd)
+	rootCmd.AddCommand(pluginCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(keyCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(renderCmd)
+}
+
+// InitTracing configures the OpenTelemetry exporters and starts the command span.
+func InitTracing() {
+	if shellSession { // Each shell command gets its own span, exported by the session's tracer
+		tracing.StartCommand("vra-cli")
+		return
+	}
+	traceOptions.Version = version
+	if err := tracing.Init(traceOptions); err != nil {
+		log.Warnln("Unable to configure tracing:", err)
+	}
+	tracing.StartCommand("vra-cli")
 }
 
 // InitConfig reads in config file and ENV variables if set.
@@ -91,6 +156,29 @@ func InitConfig() {
 		log.SetLevel(log.InfoLevel)
 	}
 
+	dryrun.Enable(APIClient.DryRun)
+	if !integrity.ValidPolicy(APIClient.Integrity) {
+		log.Fatalln("--integrity must be none, checksum or signed")
+	}
+	if APIClient.OnConflict != "" && !conflict.Valid(APIClient.OnConflict) {
+		log.Fatalln("--on-conflict must be skip, overwrite, rename or fail")
+	}
+
+	// The shell authenticates once for the whole session, and offline commands do not need to
+	if shellSession || offlineCommand() {
+		return
+	}
+	authenticate()
+}
+
+// offlineCommand returns true if the command being run works without a vRA target
+func offlineCommand() bool {
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && cmd.Annotations["offline"] == "true"
+}
+
+// loadTarget reads the configuration of the current target, without authenticating
+func loadTarget() {
 	// If we're using ENV variables
 	if os.Getenv("VRA_SERVER") != "" { // VRA_SERVER environment variable is set
 		targetConfig = *config.GetConfigFromEnv()
@@ -98,10 +186,16 @@ func InitConfig() {
 		// If we're using a config file
 		targetConfig = *config.GetConfigFromFile(cfgFile)
 	}
-
 	APIClient.Config = &targetConfig
+}
+
+// authenticate reads the target configuration and authenticates the API client
+func authenticate() {
+	loadTarget()
+	tracing.Annotate("", tracing.TargetKey.String(targetConfig.Name), tracing.ServerKey.String(targetConfig.Server))
 	err := auth.ValidateConfiguration(APIClient)
 	if err != nil {
+		tracing.Shutdown() // Flush the failed authentication spans before exiting
 		log.Fatalln(err)
 	}
 }
@@ -162,6 +256,33 @@ to quickly create a Cobra application.`,
 	Run:  func(cmd *cobra.Command, args []string) {},
 }
 
+// cancelCmd represents the cancel command
+var cancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel resources",
+	Long:  `Cancel running resources, such as Pipeline Executions`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume resources",
+	Long:  `Resume paused resources, such as Pipeline Executions`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+// rerunCmd represents the rerun command
+var rerunCmd = &cobra.Command{
+	Use:   "rerun",
+	Short: "Rerun resources",
+	Long:  `Run resources, such as Pipeline Executions, again`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
 // configCmd represents the config command
 var configCmd = &cobra.Command{
 	Use:   "config",
@@ -10,6 +10,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
+	"github.com/sammcgeown/vra-cli/pkg/util/canonical"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	"gopkg.in/yaml.v2"
 )
@@ -22,36 +25,94 @@ func ExportYaml(APIClient *types.APIClientOptions, id, name, project, path, obje
 	} else {
 		exportPath, _ = os.Getwd()
 	}
+	if APIClient.Canonical {
+		return exportCanonicalYaml(APIClient, name, project, exportPath, object)
+	}
+	content, err := GetExportYaml(APIClient, name, project, object)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(exportPath, 0755); err != nil {
+		return err
+	}
+	exportFile := filepath.Join(exportPath, name+".yaml")
+	if err := ioutil.WriteFile(exportFile, content, 0644); err != nil {
+		return err
+	}
+	return integrity.Record(exportFile, APIClient.SigningKey)
+}
+
+// exportCanonicalYaml exports the Pipeline or Endpoint without server generated fields, to a file named for its project and name
+func exportCanonicalYaml(APIClient *types.APIClientOptions, name, project, exportPath, object string) error {
+	content, err := GetExportYaml(APIClient, name, project, object)
+	if err != nil {
+		return err
+	}
+	if content, err = canonical.YAML(content); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(exportPath, 0755); err != nil {
+		return err
+	}
+	exportFile := filepath.Join(exportPath, canonical.FileName(project+" - "+name)+".yaml")
+	if err := ioutil.WriteFile(exportFile, content, 0644); err != nil {
+		return err
+	}
+	return integrity.Record(exportFile, APIClient.SigningKey)
+}
+
+// GetExportYaml returns the Code Stream export YAML of a Pipeline or Endpoint
+func GetExportYaml(APIClient *types.APIClientOptions, name, project, object string) ([]byte, error) {
 	APIClient.RESTClient.QueryParam.Set(object, name)
 	APIClient.RESTClient.QueryParam.Set("project", project)
+	defer APIClient.RESTClient.QueryParam.Del(object)
+	defer APIClient.RESTClient.QueryParam.Del("project")
 
 	queryResponse, err := APIClient.RESTClient.R().
 		SetError(&types.Exception{}).
-		SetOutput(filepath.Join(exportPath, name+".yaml")).
 		SetHeader("Accept", "application/x-yaml;charset=UTF-8").
 		Get("/codestream/api/export")
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if queryResponse.IsError() {
-		return errors.New(queryResponse.Status())
+		return nil, errors.New(queryResponse.Status())
 	}
-	return nil
+	return queryResponse.Body(), nil
+}
+
+// deletedStatus returns the batch status of an object deleted by ID. Deleting by ID does not change
+// the REST client's query parameters, so bulk deletes share APIClient between their workers.
+func deletedStatus(APIClient *types.APIClientOptions) string {
+	if APIClient.DryRun {
+		return "would delete"
+	}
+	return "deleted"
 }
 
 // ImportYaml import a yaml pipeline or endpoint
 func ImportYaml(APIClient *types.APIClientOptions, yamlPath, action, project, importType string) error {
-	var pipeline types.PipelineYaml
-	var endpoint types.EndpointYaml
-
-	APIClient.RESTClient.QueryParam.Set("action", action)
-
+	if err := integrity.Check(APIClient.Integrity, yamlPath); err != nil {
+		return err
+	}
 	yamlBytes, err := ioutil.ReadFile(yamlPath)
 	if err != nil {
 		return err
 	}
+	return ImportYamlContent(APIClient, yamlBytes, action, project, importType)
+}
+
+// ImportYamlContent import a yaml pipeline or endpoint from its content
+func ImportYamlContent(APIClient *types.APIClientOptions, yamlBytes []byte, action, project, importType string) error {
+	var pipeline types.PipelineYaml
+	var endpoint types.EndpointYaml
+
+	if importType == "endpoint" { // The import can create an Endpoint with a new ID, or rename one
+		defer cache.Invalidate(APIClient, "endpoint:")
+	}
+	APIClient.RESTClient.QueryParam.Set("action", action)
 
 	if project != "" { // If the project flag is set we need to update the project value
 		if importType == "pipeline" {
@@ -86,6 +147,9 @@ func ImportYaml(APIClient *types.APIClientOptions, yamlPath, action, project, im
 	if queryResponse.IsError() {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
+	if APIClient.DryRun { // The import was not sent, so there is no import status
+		return nil
+	}
 
 	var importResponse types.PipelineImportResponse
 	if err = yaml.Unmarshal(queryResponse.Body(), &importResponse); err != nil {
@@ -13,7 +13,9 @@ import (
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/sammcgeown/vra-cli/pkg/util/canonical"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 )
@@ -88,6 +90,7 @@ func CreateCustomIntegration(APIClient *types.APIClientOptions, name string, des
 			return nil, importErr
 		}
 	} else {
+		customIntegration = &types.CustomIntegration{}
 		customIntegration.Name = name
 		customIntegration.Description = description
 		customIntegration.Yaml = yaml
@@ -204,7 +207,7 @@ func DeleteCustomIntegration(APIClient *types.APIClientOptions, id, name string)
 }
 
 // ExportCustomIntegration - Export a custom integration
-func ExportCustomIntegration(customintegration types.CustomIntegration, exportPath string, overwrite bool) error {
+func ExportCustomIntegration(APIClient *types.APIClientOptions, customintegration types.CustomIntegration, exportPath string, overwrite bool) error {
 	_, pathError := os.Stat(exportPath) // Check if folder exists
 	if pathError != nil {
 		if os.IsNotExist(pathError) { // If it doesn't exist
@@ -215,7 +218,11 @@ func ExportCustomIntegration(customintegration types.CustomIntegration, exportPa
 	}
 
 	// Create the absolute path, with file name
-	filePath, _ := filepath.Abs(filepath.Join(exportPath, customintegration.Name+".json"))
+	fileName := customintegration.Name
+	if APIClient.Canonical {
+		fileName = canonical.FileName(fileName)
+	}
+	filePath, _ := filepath.Abs(filepath.Join(exportPath, fileName+".json"))
 	fileStat, fileErr := os.Stat(filePath)
 	if fileErr != nil {
 		if os.IsNotExist(fileErr) {
@@ -228,13 +235,22 @@ func ExportCustomIntegration(customintegration types.CustomIntegration, exportPa
 	}
 
 	ci, _ := json.MarshalIndent(customintegration, "", "  ")
+	if APIClient.Canonical {
+		var err error
+		if ci, err = canonical.JSON(customintegration, CustomIntegrationServerFields...); err != nil {
+			return err
+		}
+	}
 	writeErr := os.WriteFile(filePath, ci, 0644)
 	if writeErr != nil {
 		return writeErr
 	}
-	return nil
+	return integrity.Record(filePath, APIClient.SigningKey)
 }
 
+// CustomIntegrationServerFields - Custom Integration fields managed by the server, in addition to canonical.ServerFields
+var CustomIntegrationServerFields = []string{"version", "status"}
+
 // ImportCustomIntegration - Import Custom Integrations from the importPath
 func ImportCustomIntegration(importPath string) (*types.CustomIntegration, error) {
 	filename, _ := filepath.Abs(importPath)
@@ -0,0 +1,121 @@
+/*
+Package codestream Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package codestream
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sammcgeown/vra-cli/pkg/util/graph"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// variableReference matches a reference to a Variable, e.g. ${var.name}
+var variableReference = regexp.MustCompile(`\$\{var\.(.*?)\}`)
+
+// PipelineDependencies - the objects a Pipeline references
+type PipelineDependencies struct {
+	Variables          []string
+	Endpoints          []string
+	Pipelines          []string // Nested pipeline tasks
+	CustomIntegrations []string // Custom tasks
+}
+
+// GetPipelineDependencies returns the names of the Variables, Endpoints, nested Pipelines and Custom
+// Integrations a Pipeline references, sorted and without duplicates
+func GetPipelineDependencies(pipeline *types.Pipeline) PipelineDependencies {
+	var dependencies PipelineDependencies
+	if pipelineJSON, err := json.Marshal(pipeline); err == nil {
+		for _, v := range variableReference.FindAllStringSubmatch(string(pipelineJSON), -1) {
+			dependencies.Variables = append(dependencies.Variables, v[1])
+		}
+	}
+	if pipeline.Workspace.Endpoint != "" {
+		dependencies.Endpoints = append(dependencies.Endpoints, pipeline.Workspace.Endpoint)
+	}
+	for _, s := range pipeline.Stages {
+		stage := types.PipelineStage{}
+		mapstructure.Decode(s, &stage)
+		for n, t := range stage.Tasks {
+			task := types.PipelineTask{}
+			mapstructure.Decode(t, &task)
+			for _, e := range task.Endpoints {
+				dependencies.Endpoints = append(dependencies.Endpoints, e)
+			}
+			switch task.Type {
+			case "Pipeline":
+				dependencies.Pipelines = append(dependencies.Pipelines, task.Input.Pipeline)
+			case "Custom":
+				dependencies.CustomIntegrations = append(dependencies.CustomIntegrations, task.Input.Name)
+			}
+			log.Debugln("-- [Task]", n, "(", task.Type, ")")
+		}
+	}
+	for _, names := range []*[]string{&dependencies.Variables, &dependencies.Endpoints, &dependencies.Pipelines, &dependencies.CustomIntegrations} {
+		unique := helpers.RemoveDuplicateStrings(*names)
+		*names = nil
+		for _, name := range unique {
+			if name != "" { // Tasks that have not been configured
+				*names = append(*names, name)
+			}
+		}
+		sort.Strings(*names)
+	}
+	return dependencies
+}
+
+// GetDependencyGraph returns the graph of the objects the Pipelines depend on, following nested
+// pipeline tasks transitively. Nested Pipelines that cannot be found are marked as missing.
+func GetDependencyGraph(APIClient *types.APIClientOptions, pipelines []*types.Pipeline) (*graph.Graph, error) {
+	g := graph.New()
+	var queue []*types.Pipeline
+	for _, pipeline := range pipelines {
+		if _, added := g.Add(manifest.KindPipeline, pipeline.Project, pipeline.Name); added {
+			queue = append(queue, pipeline)
+		}
+	}
+	for len(queue) > 0 {
+		pipeline := queue[0]
+		queue = queue[1:]
+		from, _ := g.Add(manifest.KindPipeline, pipeline.Project, pipeline.Name)
+		dependencies := GetPipelineDependencies(pipeline)
+
+		for _, name := range dependencies.Pipelines {
+			node, added := g.Add(manifest.KindPipeline, pipeline.Project, name)
+			g.Connect(from, node)
+			if !added {
+				continue
+			}
+			nested, err := GetPipeline(APIClient, "", name, pipeline.Project, "")
+			if err != nil {
+				return nil, err
+			}
+			if len(nested) == 0 {
+				log.Warnln("Pipeline", pipeline.Name, "runs Pipeline", name, "which was not found in", pipeline.Project)
+				node.Missing = true
+				continue
+			}
+			queue = append(queue, nested[0])
+		}
+		for _, name := range dependencies.Variables {
+			node, _ := g.Add(manifest.KindVariable, pipeline.Project, name)
+			g.Connect(from, node)
+		}
+		for _, name := range dependencies.Endpoints {
+			node, _ := g.Add(manifest.KindEndpoint, pipeline.Project, name)
+			g.Connect(from, node)
+		}
+		for _, name := range dependencies.CustomIntegrations {
+			node, _ := g.Add(manifest.KindCustomIntegration, "", name) // Custom Integrations are not in a Project
+			g.Connect(from, node)
+		}
+	}
+	return g, nil
+}
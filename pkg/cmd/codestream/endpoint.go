@@ -10,6 +10,9 @@ import (
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
@@ -57,37 +60,66 @@ func GetEndpoint(APIClient *types.APIClientOptions, id, name, project, endpointt
 	return endpoints, err
 }
 
+// GetEndpointID resolves an endpoint name (optionally within a project) to its ID, using the local cache
+func GetEndpointID(APIClient *types.APIClientOptions, name, project string) (string, error) {
+	var id string
+	key := "endpoint:" + project + ":" + name
+	if cache.Get(APIClient, key, &id) {
+		return id, nil
+	}
+	endpoints, err := GetEndpoint(APIClient, "", name, project, "", "")
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints) == 0 {
+		return "", errors.New("Endpoint not found")
+	} else if len(endpoints) > 1 {
+		return "", fmt.Errorf("%d Endpoints named %s found, specify a project", len(endpoints), name)
+	}
+	id = endpoints[0].ID
+	cache.Set(APIClient, key, id)
+	return id, nil
+}
+
 // DeleteEndpoint deletes an endpoint
 func DeleteEndpoint(APIClient *types.APIClientOptions, id string) error {
-	queryResponse, _ := APIClient.RESTClient.R().
-		SetResult(&types.DocumentsList{}).
+	defer cache.Invalidate(APIClient, "endpoint:")
+	queryResponse, err := APIClient.RESTClient.R().
 		SetError(&types.Exception{}).
-		Get("/pipeline/api/endpoints/" + id)
+		Delete("/pipeline/api/endpoints/" + id)
 
+	if err != nil {
+		return err
+	}
 	if queryResponse.IsError() {
-		return queryResponse.Error().(error)
+		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
 	return nil
 }
 
 // DeleteEndpointByProject deletes an endpoint by project
-func DeleteEndpointByProject(APIClient *types.APIClientOptions, project string) ([]*types.Endpoint, error) {
-	var deletedEndpoints []*types.Endpoint
+func DeleteEndpointByProject(APIClient *types.APIClientOptions, project string) ([]batch.Result, error) {
 	Endpoints, err := GetEndpoint(APIClient, "", "", project, "", "")
 	if err != nil {
 		return nil, err
 	}
-	confirm := helpers.AskForConfirmation("This will attempt to delete " + fmt.Sprint(len(Endpoints)) + " Endpoints in " + project + ", are you sure?")
-	if confirm {
-
+	if APIClient.DryRun {
 		for _, endpoint := range Endpoints {
-			err := DeleteEndpoint(APIClient, endpoint.ID)
-			if err != nil {
-				log.Warnln("Unable to delete "+endpoint.Name, err)
-			}
-			deletedEndpoints = append(deletedEndpoints, endpoint)
+			log.Infoln("[dry-run] Would delete Endpoint", endpoint.Name, "("+endpoint.ID+")")
 		}
-		return deletedEndpoints, nil
+	}
+	if APIClient.DryRun || helpers.AskForConfirmation("This will attempt to delete "+fmt.Sprint(len(Endpoints))+" Endpoints in "+project+", are you sure?") {
+		names := make([]string, len(Endpoints))
+		for i, endpoint := range Endpoints {
+			names[i] = endpoint.Name
+		}
+		clients := auth.Clients(APIClient, len(names))
+		return batch.Run("Deleting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			if err := DeleteEndpoint(clients[worker], Endpoints[i].ID); err != nil {
+				return "", err
+			}
+			return deletedStatus(APIClient), nil
+		}), nil
 	}
 	return nil, errors.New("user declined")
 }
@@ -8,11 +8,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/pipelineexecution"
+	"github.com/sammcgeown/vra-cli/pkg/util/pipelineinput"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 )
@@ -26,6 +32,9 @@ func GetExecution(APIClient *types.APIClientOptions, id string, project string,
 			SetError(&types.Exception{}).
 			Get("/pipeline/api/executions/" + id)
 		if err != nil {
+			return nil, err
+		}
+		if queryResponse.IsError() {
 			return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
 		}
 		arrExecutions = append(arrExecutions, queryResponse.Result().(*types.Executions))
@@ -35,7 +44,73 @@ func GetExecution(APIClient *types.APIClientOptions, id string, project string,
 	APIClient.RESTClient.QueryParam.Set("$top", strconv.Itoa(APIClient.Pagination.PageSize))
 	APIClient.RESTClient.QueryParam.Set("$page", strconv.Itoa(APIClient.Pagination.Page))
 	APIClient.RESTClient.QueryParam.Set("$skip", strconv.Itoa(APIClient.Pagination.Skip))
+	defer func() {
+		for _, param := range []string{"$top", "$page", "$skip", "$filter"} {
+			APIClient.RESTClient.QueryParam.Del(param)
+		}
+	}()
+
+	if filters := executionFilters(project, status, name, nested, rollback); len(filters) > 0 {
+		APIClient.RESTClient.QueryParam.Set("$filter", "("+strings.Join(filters, ") and (")+")")
+		log.Debugln(APIClient.RESTClient.QueryParam)
+	}
+
+	queryResponse, err := APIClient.RESTClient.R().
+		SetResult(&types.DocumentsList{}).
+		SetError(&types.Exception{}).
+		Get("/pipeline/api/executions")
+
+	if err != nil {
+		return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
+	}
+
+	return decodeExecutions(queryResponse.Result().(*types.DocumentsList).Documents)
+}
+
+// GetExecutionsSince - returns every execution with the status created after since, paging until all
+// the executions have been read
+func GetExecutionsSince(APIClient *types.APIClientOptions, project string, status string, name string, since time.Time) ([]*types.Executions, error) {
+	pageSize := APIClient.Pagination.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	filters := executionFilters(project, status, name, false, false)
+	if !since.IsZero() {
+		filters = append(filters, "(_createTimeInMicros gt "+strconv.FormatInt(since.UnixMicro(), 10)+")")
+	}
+	query := map[string]string{"$top": strconv.Itoa(pageSize)}
+	if len(filters) > 0 {
+		query["$filter"] = "(" + strings.Join(filters, ") and (") + ")"
+	}
+	var arrExecutions []*types.Executions
+	for {
+		queryResponse, err := APIClient.RESTClient.R().
+			SetQueryParams(query).
+			SetQueryParam("$skip", strconv.Itoa(len(arrExecutions))).
+			SetResult(&types.DocumentsList{}).
+			SetError(&types.Exception{}).
+			Get("/pipeline/api/executions")
+		if err != nil {
+			return nil, err
+		}
+		if queryResponse.IsError() {
+			return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
+		}
+		page := queryResponse.Result().(*types.DocumentsList)
+		executions, err := decodeExecutions(page.Documents)
+		if err != nil {
+			return nil, err
+		}
+		arrExecutions = append(arrExecutions, executions...)
+		log.Debugln("Read", len(arrExecutions), "of", page.TotalCount, "executions")
+		if len(executions) == 0 || len(arrExecutions) >= page.TotalCount {
+			return arrExecutions, nil
+		}
+	}
+}
 
+// executionFilters returns the $filter terms for executions
+func executionFilters(project string, status string, name string, nested bool, rollback bool) []string {
 	var filters []string
 	if status != "" {
 		filters = append(filters, "(status eq '"+strings.ToUpper(status)+"')")
@@ -52,26 +127,25 @@ func GetExecution(APIClient *types.APIClientOptions, id string, project string,
 	if project != "" {
 		filters = append(filters, "(project eq '"+project+"')")
 	}
-	if len(filters) > 0 {
-		APIClient.RESTClient.QueryParam.Set("$filter", "("+strings.Join(filters, ") and (")+")")
-		log.Debugln(APIClient.RESTClient.QueryParam)
-	}
-
-	queryResponse, err := APIClient.RESTClient.R().
-		SetResult(&types.DocumentsList{}).
-		SetError(&types.Exception{}).
-		Get("/pipeline/api/executions")
-
-	if err != nil {
-		return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
-	}
+	return filters
+}
 
-	for _, value := range queryResponse.Result().(*types.DocumentsList).Documents {
+// decodeExecutions decodes the documents of a list of executions
+func decodeExecutions(documents map[string]interface{}) ([]*types.Executions, error) {
+	var arrExecutions []*types.Executions
+	for _, value := range documents {
 		c := types.Executions{}
-		mapstructure.Decode(value, &c)
+		// Decode by JSON name, so that fields such as _createTimeInMicros and _nested are set
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Result: &c})
+		if err != nil {
+			return nil, err
+		}
+		if err := decoder.Decode(value); err != nil {
+			log.Debugln("Unable to decode execution:", err)
+		}
 		arrExecutions = append(arrExecutions, &c)
 	}
-	return arrExecutions, err
+	return arrExecutions, nil
 }
 
 // DeleteExecution - deletes an execution by ID
@@ -82,31 +156,39 @@ func DeleteExecution(APIClient *types.APIClientOptions, id string) (bool, error)
 		Delete("/pipeline/api/executions/" + id)
 
 	if err != nil {
+		return false, err
+	}
+	if queryResponse.IsError() {
 		return false, errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
-
-	return true, err
+	return true, nil
 }
 
 // DeleteExecutions - deletes an execution by project, status, or pipeline name
-func DeleteExecutions(APIClient *types.APIClientOptions, project string, status string, name string, nested bool, rollback bool) ([]*types.Executions, error) {
-	var deletedExecutions []*types.Executions
+func DeleteExecutions(APIClient *types.APIClientOptions, project string, status string, name string, nested bool, rollback bool) ([]batch.Result, error) {
 	Executions, err := GetExecution(APIClient, "", project, status, name, nested, rollback)
 	if err != nil {
 		return nil, err
 	}
-	if !APIClient.Confirm {
+	if APIClient.DryRun {
+		for _, Execution := range Executions {
+			log.Infoln("[dry-run] Would delete Execution", Execution.Name+"#"+fmt.Sprint(Execution.Index), "("+Execution.ID+")")
+		}
+	} else if !APIClient.Confirm {
 		APIClient.Confirm = helpers.AskForConfirmation("This will attempt to delete " + fmt.Sprint(len(Executions)) + ", are you sure?")
 	}
-	if APIClient.Confirm {
-		for _, Execution := range Executions {
-			_, err := DeleteExecution(APIClient, Execution.ID)
-			if err != nil {
-				log.Warnln("Unable to delete "+Execution.ID, err)
-			}
-			deletedExecutions = append(deletedExecutions, Execution)
+	if APIClient.Confirm || APIClient.DryRun {
+		names := make([]string, len(Executions))
+		for i, Execution := range Executions {
+			names[i] = Execution.Name + "#" + fmt.Sprint(Execution.Index)
 		}
-		return deletedExecutions, nil
+		clients := auth.Clients(APIClient, len(names))
+		return batch.Run("Deleting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			if _, err := DeleteExecution(clients[worker], Executions[i].ID); err != nil {
+				return "", err
+			}
+			return deletedStatus(APIClient), nil
+		}), nil
 	}
 	return nil, errors.New("user declined")
 
@@ -131,14 +213,120 @@ func CreateExecution(APIClient *types.APIClientOptions, id string, inputs string
 	if err != nil {
 		return nil, err
 	}
-	queryResponse, _ := APIClient.RESTClient.R().
+	queryResponse, err := APIClient.RESTClient.R().
 		SetBody(executionBytes).
 		SetResult(&types.CreateExecutionResponse{}).
 		SetError(&types.Exception{}).
 		Post("/pipeline/api/pipelines/" + id + "/executions")
 
 	if err != nil {
+		return nil, err
+	}
+	if queryResponse.IsError() {
 		return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
 	return queryResponse.Result().(*types.CreateExecutionResponse), nil
 }
+
+// WatchExecution polls an Execution every interval until it ends (see pipelineexecution.Watch)
+func WatchExecution(APIClient *types.APIClientOptions, id string, interval, timeout time.Duration, update func(*types.Executions)) (*types.Executions, error) {
+	return pipelineexecution.Watch(func() (*types.Executions, error) {
+		executions, err := GetExecution(APIClient, id, "", "", "", false, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(executions) == 0 {
+			return nil, errors.New("execution " + id + " not found")
+		}
+		return executions[0], nil
+	}, interval, timeout, update)
+}
+
+// CancelExecution - cancels a running Execution
+func CancelExecution(APIClient *types.APIClientOptions, id string, reason string) error {
+	queryResponse, err := APIClient.RESTClient.R().
+		SetBody(map[string]string{"reason": reason}).
+		SetError(&types.Exception{}).
+		Post("/codestream/api/executions/" + id + "/cancel")
+
+	if err != nil {
+		return err
+	}
+	if queryResponse.IsError() {
+		return errors.New(queryResponse.Error().(*types.Exception).Message)
+	}
+	return nil
+}
+
+// ResumeExecution - resumes a paused Execution
+func ResumeExecution(APIClient *types.APIClientOptions, id string) error {
+	queryResponse, err := APIClient.RESTClient.R().
+		SetError(&types.Exception{}).
+		Post("/codestream/api/executions/" + id + "/resume")
+
+	if err != nil {
+		return err
+	}
+	if queryResponse.IsError() {
+		return errors.New(queryResponse.Error().(*types.Exception).Message)
+	}
+	return nil
+}
+
+// GetExecutionPipelineID returns the ID of the Pipeline an Execution ran
+func GetExecutionPipelineID(APIClient *types.APIClientOptions, execution *types.Executions) (string, error) {
+	if execution.PipelineLink != "" {
+		return path.Base(execution.PipelineLink), nil
+	}
+	pipelines, err := GetPipeline(APIClient, "", execution.Name, execution.Project, "")
+	if err != nil {
+		return "", err
+	}
+	if len(pipelines) != 1 {
+		return "", fmt.Errorf("unable to find Pipeline %s in Project %s", execution.Name, execution.Project)
+	}
+	return pipelines[0].ID, nil
+}
+
+// RerunExecution - creates an Execution of the same Pipeline as an earlier Execution, with its inputs and
+// comments. Inputs are overridden by inputs, and comments by comments if it is not empty. Inputs the
+// Pipeline no longer declares are not passed on.
+func RerunExecution(APIClient *types.APIClientOptions, id string, inputs map[string]string, comments string) (*types.CreateExecutionResponse, error) {
+	executions, err := GetExecution(APIClient, id, "", "", "", false, false)
+	if err != nil {
+		return nil, err
+	}
+	execution := executions[0]
+	pipelineID, err := GetExecutionPipelineID(APIClient, execution)
+	if err != nil {
+		return nil, err
+	}
+	pipelines, err := GetPipeline(APIClient, pipelineID, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, fmt.Errorf("the Pipeline of execution %s#%d no longer exists", execution.Name, execution.Index)
+	}
+	declared, _ := pipelines[0].Input.(map[string]interface{})
+	original, _ := execution.Input.(map[string]interface{})
+	originalInputs, err := pipelineinput.Strings(original)
+	if err != nil {
+		return nil, err
+	}
+	for _, input := range pipelineinput.Undeclared(originalInputs, declared) {
+		log.Debugln("Pipeline", execution.Name, "no longer has input", input)
+	}
+	rerunInputs, err := pipelineinput.Rerun(originalInputs, inputs, declared)
+	if err != nil {
+		return nil, err
+	}
+	inputBytes, err := json.Marshal(rerunInputs)
+	if err != nil {
+		return nil, err
+	}
+	if comments == "" {
+		comments = execution.Comments
+	}
+	return CreateExecution(APIClient, pipelineID, string(inputBytes), comments)
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
@@ -30,7 +32,8 @@ func GetPipeline(APIClient *types.APIClientOptions, id string, name string, proj
 		filters = append(filters, "(project eq '"+project+"')")
 	}
 	if len(filters) > 0 {
-		APIClient.RESTClient.QueryParam.Add("$filter", "("+strings.Join(filters, ") and (")+")")
+		APIClient.RESTClient.QueryParam.Set("$filter", "("+strings.Join(filters, ") and (")+")")
+		defer APIClient.RESTClient.QueryParam.Del("$filter")
 		log.Debugln(APIClient.RESTClient.QueryParam)
 	}
 
@@ -81,22 +84,28 @@ func DeletePipeline(APIClient *types.APIClientOptions, id string) (*types.Pipeli
 }
 
 // DeletePipelineInProject - Delete Code Stream Pipeline by Project
-func DeletePipelineInProject(APIClient *types.APIClientOptions, project string) ([]*types.Pipeline, error) {
-	var deletedPipes []*types.Pipeline
+func DeletePipelineInProject(APIClient *types.APIClientOptions, project string) ([]batch.Result, error) {
 	pipelines, err := GetPipeline(APIClient, "", "", project, "")
 	if err != nil {
 		return nil, err
 	}
-	confirm := helpers.AskForConfirmation("This will attempt to delete " + fmt.Sprint(len(pipelines)) + " Pipelines in " + project + ", are you sure?")
-	if confirm {
+	if APIClient.DryRun {
 		for _, pipeline := range pipelines {
-			deletedPipe, err := DeletePipeline(APIClient, pipeline.ID)
-			if err != nil {
-				log.Warnln("Unable to delete "+pipeline.Name, err)
-			}
-			deletedPipes = append(deletedPipes, deletedPipe)
+			log.Infoln("[dry-run] Would delete Pipeline", pipeline.Name, "("+pipeline.ID+")")
+		}
+	}
+	if APIClient.DryRun || helpers.AskForConfirmation("This will attempt to delete "+fmt.Sprint(len(pipelines))+" Pipelines in "+project+", are you sure?") {
+		names := make([]string, len(pipelines))
+		for i, pipeline := range pipelines {
+			names[i] = pipeline.Name
 		}
-		return deletedPipes, nil
+		clients := auth.Clients(APIClient, len(names))
+		return batch.Run("Deleting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			if _, err := DeletePipeline(clients[worker], pipelines[i].ID); err != nil {
+				return "", err
+			}
+			return deletedStatus(APIClient), nil
+		}), nil
 	}
 
 	return nil, errors.New("user declined")
@@ -12,8 +12,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/canonical"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
@@ -47,6 +52,7 @@ func GetVariable(APIClient *types.APIClientOptions, id, name, project, exportPat
 	}
 	if len(filters) > 0 {
 		APIClient.RESTClient.QueryParam.Set("$filter", "("+strings.Join(filters, ") and (")+")")
+		defer APIClient.RESTClient.QueryParam.Del("$filter")
 		log.Debugln(APIClient.RESTClient.QueryParam)
 	}
 
@@ -67,7 +73,9 @@ func GetVariable(APIClient *types.APIClientOptions, id, name, project, exportPat
 		mapstructure.Decode(value, &c)
 		arrVariables = append(arrVariables, &c)
 		if exportPath != "" {
-			ExportVariable(c, exportPath)
+			if err := ExportVariable(APIClient, c, exportPath); err != nil {
+				log.Errorln("Unable to export variable", c.Name, err)
+			}
 		}
 	}
 	return arrVariables, err
@@ -129,7 +137,11 @@ func UpdateVariable(APIClient *types.APIClientOptions, id string, name string, d
 func DeleteVariable(APIClient *types.APIClientOptions, id string) (bool, error) {
 	queryResponse, err := APIClient.RESTClient.R().
 		SetResult(&types.VariableResponse{}).
+		SetError(&types.Exception{}).
 		Delete("/pipeline/api/variables/" + id)
+	if err != nil {
+		return false, err
+	}
 	if queryResponse.IsError() {
 		return false, errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
@@ -137,41 +149,68 @@ func DeleteVariable(APIClient *types.APIClientOptions, id string) (bool, error)
 }
 
 // DeleteVariableByProject - Delete all Variables in a Project
-func DeleteVariableByProject(APIClient *types.APIClientOptions, project string) ([]*types.VariableResponse, error) {
-	var deletedVariables []*types.VariableResponse
+func DeleteVariableByProject(APIClient *types.APIClientOptions, project string) ([]batch.Result, error) {
 	Variables, err := GetVariable(APIClient, "", "", project, "")
 	if err != nil {
 		return nil, err
 	}
 	if len(Variables) == 0 {
 		log.Infoln("No variables found for project:", project)
-		return deletedVariables, nil
+		return nil, nil
 	}
-	if !APIClient.Confirm {
+	if APIClient.DryRun {
+		for _, Variable := range Variables {
+			log.Infoln("[dry-run] Would delete Variable", Variable.Name, "("+Variable.ID+")")
+		}
+	} else if !APIClient.Confirm {
 		APIClient.Confirm = helpers.AskForConfirmation("This will attempt to delete " + fmt.Sprint(len(Variables)) + " variables in " + project + ", are you sure?")
 	}
-	if APIClient.Confirm {
-		for _, Variable := range Variables {
-			_, err := DeleteVariable(APIClient, Variable.ID)
-			if err != nil {
-				log.Warnln("Unable to delete "+Variable.Name, err)
-			}
-			deletedVariables = append(deletedVariables, Variable)
+	if APIClient.Confirm || APIClient.DryRun {
+		names := make([]string, len(Variables))
+		for i, Variable := range Variables {
+			names[i] = Variable.Name
 		}
-		return deletedVariables, nil
+		clients := auth.Clients(APIClient, len(names))
+		return batch.Run("Deleting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			if _, err := DeleteVariable(clients[worker], Variables[i].ID); err != nil {
+				return "", err
+			}
+			return deletedStatus(APIClient), nil
+		}), nil
 	}
 	return nil, errors.New("user declined")
 }
 
+// variablesFile serialises the appends of concurrent exports to the same variables.yaml
+var variablesFile sync.Mutex
+
 // ExportVariable - Export a variable to YAML
-func ExportVariable(variable interface{}, exportPath string) {
+func ExportVariable(APIClient *types.APIClientOptions, variable interface{}, exportPath string) error {
 	var exportFile string
 	// variable will be a types.VariableResponse, so lets remap to types.VariableRequest
 	c := types.VariableRequest{}
 	mapstructure.Decode(variable, &c)
 	yaml, err := yaml.Marshal(c)
 	if err != nil {
-		log.Errorln("Unable to export variable ", c.Name)
+		return err
+	}
+
+	if APIClient.Canonical { // One file per variable, in the export folder
+		if filepath.Ext(exportPath) == ".yaml" {
+			exportPath = filepath.Dir(exportPath)
+		}
+		if yaml, err = canonical.YAML(yaml); err != nil {
+			return err
+		}
+		os.MkdirAll(exportPath, 0755)
+		exportFile = filepath.Join(exportPath, canonical.FileName(c.Project+" - "+c.Name)+".yaml")
+		if err := ioutil.WriteFile(exportFile, yaml, 0644); err != nil {
+			return err
+		}
+		if err := integrity.Record(exportFile, APIClient.SigningKey); err != nil {
+			log.Errorln("Unable to record the checksum of", exportFile, err)
+		}
+		return nil
 	}
 
 	if filepath.Ext(exportPath) != ".yaml" {
@@ -180,28 +219,35 @@ func ExportVariable(variable interface{}, exportPath string) {
 		exportFile = exportPath
 	}
 
+	variablesFile.Lock()
+	defer variablesFile.Unlock()
 	file, err := os.OpenFile(exportFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	defer file.Close()
-	file.WriteString("---\n" + string(yaml))
+	_, err = file.WriteString("---\n" + string(yaml))
+	return err
 }
 
-// ImportVariables - Import variables from the filePath
+// ImportVariables - Import variables from the filePath, or the YAML files in the filePath folder
 func ImportVariables(filePath string) []types.VariableRequest {
 	var returnVariables []types.VariableRequest
-	filename, _ := filepath.Abs(filePath)
-	yamlFile, err := ioutil.ReadFile(filename)
-	if err != nil {
-		panic(err)
-	}
-	reader := bytes.NewReader(yamlFile)
-	decoder := yaml.NewDecoder(reader)
-	var request types.VariableRequest
-	for decoder.Decode(&request) == nil {
-		returnVariables = append(returnVariables, request)
+	for _, filename := range helpers.GetFilePaths(filePath, ".yaml") {
+		yamlFile, err := ioutil.ReadFile(filename)
+		if err != nil {
+			panic(err)
+		}
+		reader := bytes.NewReader(yamlFile)
+		decoder := yaml.NewDecoder(reader)
+		for {
+			var request types.VariableRequest
+			if decoder.Decode(&request) != nil {
+				break
+			}
+			returnVariables = append(returnVariables, request)
+		}
 	}
 	return returnVariables
 }
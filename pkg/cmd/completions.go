@@ -0,0 +1,243 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"sort"
+
+	"github.com/sammcgeown/vra-cli/pkg/cmd/cloudassembly"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// completion - an object offered as a shell completion
+type completion struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+// completionSource - lists the objects of one kind for shell completion
+type completionSource struct {
+	filter string                                    // Flag used to filter the objects, if any
+	list   func(filter string) ([]completion, error) // Lists the objects, filtered by the value of the filter flag
+}
+
+// completionSources - the objects that can be completed, by kind
+var completionSources = map[string]completionSource{
+	"pipeline": {filter: "project", list: func(project string) ([]completion, error) {
+		var completions []completion
+		pipelines, err := codestream.GetPipeline(APIClient, "", "", project, "")
+		for _, p := range pipelines {
+			completions = append(completions, completion{Name: p.Name, ID: p.ID})
+		}
+		return completions, err
+	}},
+	"variable": {filter: "project", list: func(project string) ([]completion, error) {
+		var completions []completion
+		variables, err := codestream.GetVariable(APIClient, "", "", project, "")
+		for _, v := range variables {
+			completions = append(completions, completion{Name: v.Name, ID: v.ID, Type: v.Type})
+		}
+		return completions, err
+	}},
+	"endpoint": {filter: "project", list: func(project string) ([]completion, error) {
+		var completions []completion
+		endpoints, err := codestream.GetEndpoint(APIClient, "", "", project, "", "")
+		for _, e := range endpoints {
+			completions = append(completions, completion{Name: e.Name, ID: e.ID, Type: e.Type})
+		}
+		return completions, err
+	}},
+	"cloudtemplate": {filter: "project", list: func(project string) ([]completion, error) {
+		var completions []completion
+		templates, err := cloudassembly.GetCloudTemplate(APIClient, "", "", project)
+		for _, t := range templates {
+			completions = append(completions, completion{Name: t.Name, ID: t.ID})
+		}
+		return completions, err
+	}},
+	"project": {list: func(string) ([]completion, error) {
+		var completions []completion
+		projects, err := cloudassembly.GetProject(APIClient, "", "")
+		for _, p := range projects {
+			if p.ID != nil {
+				completions = append(completions, completion{Name: p.Name, ID: *p.ID})
+			}
+		}
+		return completions, err
+	}},
+	"workflow": {filter: "category", list: func(category string) ([]completion, error) {
+		var completions []completion
+		defer summary()()
+		workflows, err := orchestrator.GetWorkflow(APIClient, "", category, "")
+		for _, w := range workflows {
+			completions = append(completions, completion{Name: w.Name, ID: w.ID})
+		}
+		return completions, err
+	}},
+	"action": {filter: "category", list: func(category string) ([]completion, error) {
+		var completions []completion
+		defer summary()()
+		actions, err := orchestrator.GetAction(APIClient, "", category, "")
+		for _, a := range actions {
+			completions = append(completions, completion{Name: a.Name, ID: a.ID})
+		}
+		return completions, err
+	}},
+	"package": {list: func(string) ([]completion, error) {
+		var completions []completion
+		packages, err := orchestrator.GetPackage(APIClient, "")
+		for _, p := range packages {
+			completions = append(completions, completion{Name: p.Name, ID: p.ID})
+		}
+		return completions, err
+	}},
+	"category": {filter: "type", list: func(categoryType string) ([]completion, error) {
+		var completions []completion
+		defer summary()()
+		categories, err := orchestrator.GetCategory(APIClient, false, categoryType)
+		for _, c := range categories {
+			completions = append(completions, completion{Name: c.Name, ID: c.ID, Type: c.Type})
+		}
+		return completions, err
+	}},
+}
+
+// summary requests summaries of vRO objects, returning a function that restores the previous setting
+func summary() func() {
+	previous := APIClient.Summary
+	APIClient.Summary = true
+	return func() { APIClient.Summary = previous }
+}
+
+// invalidateCompletions removes the cached completions of the objects a command can change. It runs
+// before the command, so that objects are listed again even if the command fails part way through.
+func invalidateCompletions(cmd *cobra.Command) {
+	if !cmd.HasParent() {
+		return
+	}
+	switch cmd.Parent().Name() {
+	case "create", "update", "delete":
+		cache.Invalidate(APIClient, "completion:"+cmd.Name()+":")
+	}
+	if !cmd.Parent().HasParent() {
+		switch cmd.Name() {
+		case "apply", "restore", "migrate", "sync":
+			cache.Invalidate(APIClient, "completion:")
+		}
+	}
+}
+
+// listCompletions returns the objects of a kind, using the local cache where possible
+func listCompletions(kind string, filter string) ([]completion, error) {
+	var completions []completion
+	key := "completion:" + kind + ":" + filter
+	if cache.Get(APIClient, key, &completions) {
+		return completions, nil
+	}
+	completions, err := completionSources[kind].list(filter)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(APIClient, key, completions)
+	return completions, nil
+}
+
+// completeObjects returns a flag completion function that offers the names, IDs or types of the objects of a kind
+func completeObjects(kind string, field string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var filter string
+		if flag := completionSources[kind].filter; flag != "" && cmd.Flags().Lookup(flag) != nil {
+			filter, _ = cmd.Flags().GetString(flag)
+		}
+		objects, err := listCompletions(kind, filter)
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var completions []string
+		seen := make(map[string]bool)
+		for _, o := range objects {
+			var value string
+			switch field {
+			case "id":
+				value = o.ID + "\t" + o.Name
+			case "type":
+				value = o.Type
+			default:
+				value = o.Name
+			}
+			if value != "" && !seen[value] {
+				seen[value] = true
+				completions = append(completions, value)
+			}
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// registerCompletions adds dynamic completion of the --name, --id, --project, --category
+// and --type flags of a get/update/delete command for objects of the given kind
+func registerCompletions(cmd *cobra.Command, kind string) {
+	if cmd.ValidArgsFunction == nil {
+		cmd.ValidArgsFunction = cobra.NoFileCompletions
+	}
+	if _, ok := completionSources[kind]; ok {
+		registerFlagCompletion(cmd, "name", completeObjects(kind, "name"))
+		registerFlagCompletion(cmd, "id", completeObjects(kind, "id"))
+	}
+	registerFlagCompletion(cmd, "project", completeObjects("project", "name"))
+	switch kind {
+	case "workflow":
+		registerFlagCompletion(cmd, "category", completeCategories("WorkflowCategory"))
+	case "action":
+		registerFlagCompletion(cmd, "category", completeCategories("ScriptModuleCategory"))
+	case "variable":
+		registerFlagCompletion(cmd, "type", fixedCompletions("REGULAR", "SECRET", "RESTRICTED"))
+	case "category":
+		registerFlagCompletion(cmd, "type", fixedCompletions("ResourceElementCategory", "ConfigurationElementCategory", "WorkflowCategory", "PolicyTemplateCategory", "ScriptModuleCategory"))
+	case "endpoint":
+		registerFlagCompletion(cmd, "type", completeObjects(kind, "type"))
+	}
+}
+
+// completeCategories returns a flag completion function that offers the names of the categories of a type
+func completeCategories(categoryType string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		categories, err := listCompletions("category", categoryType)
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var completions []string
+		for _, c := range categories {
+			completions = append(completions, c.Name)
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// fixedCompletions returns a flag completion function that offers a fixed set of values
+func fixedCompletions(values ...string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// registerFlagCompletion registers a completion function if the command has the flag
+func registerFlagCompletion(cmd *cobra.Command, flag string, fn func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return
+	}
+	if err := cmd.RegisterFlagCompletionFunc(flag, fn); err != nil {
+		log.Debugln("Unable to register completion for", cmd.CommandPath(), "--"+flag, err)
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/template"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// onConflict returns the --on-conflict policy, or the command's default policy if it is not set
+func onConflict(defaultPolicy string) string {
+	if APIClient.OnConflict != "" {
+		return APIClient.OnConflict
+	}
+	return defaultPolicy
+}
+
+// orchestratorPolicy returns the conflict policy for vRO imports, which overwrite existing objects with --force
+func orchestratorPolicy() string {
+	if APIClient.Force {
+		return onConflict(conflict.Overwrite)
+	}
+	return onConflict(conflict.Fail)
+}
+
+// importOrchestratorFile imports a vRO Workflow, Action or Package using the conflict policy. vRO identifies
+// objects by the ID in the exported file, so they cannot be renamed.
+func importOrchestratorFile(APIClient *types.APIClientOptions, policy, kind string, importFile func() error) (string, error) {
+	if policy == conflict.Rename {
+		return "", errors.New(kind + "s cannot be renamed, vRO identifies them by the ID in the exported file")
+	}
+	force := APIClient.Force
+	APIClient.Force = policy == conflict.Overwrite // Overwrite the existing object
+	defer func() { APIClient.Force = force }()
+
+	err := importFile()
+	if errors.Is(err, conflict.ErrExists) && policy == conflict.Skip {
+		return "skipped", nil
+	}
+	return "imported", err
+}
+
+// checkPackageConflict returns conflict.ErrExists if the Package exists, unless it is being overwritten
+func checkPackageConflict(APIClient *types.APIClientOptions, file string, options types.ImportPackageOptions) error {
+	if APIClient.Force {
+		return nil
+	}
+	details, err := orchestrator.GetPackageDetails(APIClient, file, options)
+	if err != nil {
+		return err
+	}
+	if details.PackageAlreadyExists {
+		return fmt.Errorf("package %s %w", details.PackageName, conflict.ErrExists)
+	}
+	return nil
+}
+
+// documentImporter - the operations an import makes on the target, replaced by a fake in tests
+type documentImporter struct {
+	apply    func(APIClient *types.APIClientOptions, document *manifest.Document, policy string) (string, error)
+	snapshot func(APIClient *types.APIClientOptions, document *manifest.Document) (*manifest.Document, error)
+	delete   func(APIClient *types.APIClientOptions, document *manifest.Document) (string, error)
+}
+
+// importer makes the changes of imports on the target
+var importer = documentImporter{apply: applyDocument, snapshot: snapshotDocument, delete: deleteDocument}
+
+// importDocuments creates the objects described by the documents, resolving conflicts with
+// existing objects using the policy, and returns the outcome for each document. The documents are
+// imported concurrently, a level of dependencies at a time (see manifest.Levels). With --atomic,
+// the import stops at the first failure, the objects it created are deleted and the objects it
+// overwrote are restored.
+func importDocuments(documents []*manifest.Document, policy string) []applyResult {
+	results := make([]applyResult, len(documents))
+	for i, document := range documents {
+		results[i] = applyResult{Kind: document.Kind, Name: document.Name, Project: document.Project}
+	}
+	clients := workerClients(len(documents))
+	var mutex sync.Mutex
+	// Results of the objects created or overwritten, in the order they were changed, and the
+	// overwritten objects as they were before the import, to roll back
+	var changed []int
+	snapshots := make([]*manifest.Document, len(documents))
+	failed := false
+	for _, level := range manifest.Levels(documents) {
+		names := make([]string, len(level))
+		for i, index := range level {
+			names[i] = documents[index].String()
+		}
+		batch.Run("Importing", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			index := level[i]
+			mutex.Lock()
+			stop := failed && APIClient.Atomic
+			mutex.Unlock()
+			if stop {
+				results[index].Result = "not imported"
+				return results[index].Result, nil
+			}
+			var snapshot *manifest.Document
+			var err error
+			if APIClient.Atomic && policy == conflict.Overwrite {
+				if snapshot, err = importer.snapshot(clients[worker], documents[index]); err != nil {
+					err = fmt.Errorf("unable to save the existing object to roll back to: %w", err)
+				}
+			}
+			action := ""
+			if err == nil {
+				action, err = importer.apply(clients[worker], documents[index], policy)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				log.Errorln("Unable to import", documents[index], err)
+				results[index].Result, results[index].Error = "failed", err.Error()
+				failed = true
+				return "", err
+			}
+			log.Debugln(documents[index], action)
+			results[index].Result = action
+			if strings.HasPrefix(action, "created") || (action == "updated" && snapshot != nil) {
+				snapshots[index] = snapshot
+				changed = append(changed, index)
+			}
+			return action, nil
+		})
+	}
+	if failed && APIClient.Atomic {
+		rollbackImport(documents, results, changed, snapshots)
+	}
+	return results
+}
+
+// rollbackImport undoes the changes of an import in the reverse order they were made, deleting the
+// objects it created and restoring the snapshots of the objects it overwrote
+func rollbackImport(documents []*manifest.Document, results []applyResult, changed []int, snapshots []*manifest.Document) {
+	log.Warnln("Rolling back", len(changed), "changed objects")
+	for i := len(changed) - 1; i >= 0; i-- {
+		index := changed[i]
+		var err error
+		if snapshots[index] != nil {
+			_, err = importer.apply(APIClient, snapshots[index], conflict.Overwrite)
+		} else {
+			_, err = importer.delete(APIClient, documents[index])
+		}
+		if err != nil {
+			log.Errorln("Unable to roll back", documents[index], err)
+			results[index].Result, results[index].Error = "rollback failed", err.Error()
+		} else {
+			results[index].Result = "rolled back"
+		}
+	}
+	for _, r := range results {
+		if r.Result == "updated" || r.Result == "imported" {
+			log.Warnln(r.Kind, r.Name, "was", r.Result, "and cannot be rolled back")
+		}
+	}
+}
+
+// reportImport prints the outcome of an import, and exits if any object failed
+func reportImport(results []applyResult) {
+	if len(results) == 0 {
+		return
+	}
+	printApplyResults(results)
+	failed := 0
+	for _, r := range results {
+		if r.Result == "failed" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Fatalln(failed, "of", len(results), "objects failed to import")
+	}
+}
+
+// importFiles imports the manifests, or Code Stream exports, in each file, moving them to project if it is set.
+// With --atomic, nothing is imported unless every file can be read.
+func importFiles(paths []string, project, policy string) []applyResult {
+	var results []applyResult
+	var documents []*manifest.Document
+	values, valuesErr := template.LoadValues(valuesFiles)
+	for _, path := range paths {
+		err := integrity.Check(APIClient.Integrity, path)
+		var fileDocuments []*manifest.Document
+		if err == nil && valuesErr != nil && template.IsTemplate(path) {
+			err = fmt.Errorf("unable to load values: %w", valuesErr)
+		} else if err == nil {
+			fileDocuments, err = loadImportFile(path, values)
+		}
+		for _, document := range fileDocuments {
+			if err == nil && project != "" {
+				err = document.SetProject(project)
+			}
+		}
+		if err != nil {
+			log.Errorln("Unable to import", path, err)
+			results = append(results, applyResult{Name: filepath.Base(path), Project: project, Result: "failed", Error: err.Error()})
+			continue
+		}
+		documents = append(documents, fileDocuments...)
+	}
+	if len(results) > 0 && APIClient.Atomic {
+		return results
+	}
+	return append(results, importDocuments(documents, policy)...)
+}
+
+// importOrchestratorPath imports a vRO Workflow, Action or Package file using the conflict policy
+func importOrchestratorPath(APIClient *types.APIClientOptions, kind, path, policy string, importFile func() error) applyResult {
+	result := applyResult{Kind: kind, Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	action, err := importOrchestratorFile(APIClient, policy, kind, importFile)
+	if err != nil {
+		log.Errorln("Unable to import", path, err)
+		result.Result, result.Error = "failed", err.Error()
+	} else {
+		result.Result = action
+	}
+	return result
+}
+
+// importOrchestratorPaths imports vRO Workflow or Action files concurrently using the conflict policy
+func importOrchestratorPaths(kind string, paths []string, policy string, importFile func(APIClient *types.APIClientOptions, path string) error) []applyResult {
+	results := make([]applyResult, len(paths))
+	clients := workerClients(len(paths))
+	batch.Run("Importing", paths, APIClient.Parallel, func(worker, i int) (string, error) {
+		log.Debugln("Importing", strings.ToLower(kind)+":", paths[i])
+		results[i] = importOrchestratorPath(clients[worker], kind, paths[i], policy, func() error {
+			return importFile(clients[worker], paths[i])
+		})
+		if results[i].Error != "" {
+			return "", errors.New(results[i].Error)
+		}
+		return results[i].Result, nil
+	})
+	return results
+}
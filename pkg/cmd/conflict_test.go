@@ -0,0 +1,147 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+)
+
+// fakeTarget - a target holding the description of each object by name, for testing imports
+type fakeTarget struct {
+	mutex        sync.Mutex
+	objects      map[string]string
+	fail         map[string]bool // Objects that fail to import
+	failSnapshot map[string]bool // Objects that cannot be snapshotted
+	snapshots    int
+}
+
+// useFakeTarget replaces the importer with one that changes target, for the duration of a test
+func useFakeTarget(t *testing.T, target *fakeTarget, atomic bool) {
+	saved, atomicSaved, parallel, progress := importer, APIClient.Atomic, APIClient.Parallel, batch.Progress
+	t.Cleanup(func() {
+		importer, APIClient.Atomic, APIClient.Parallel, batch.Progress = saved, atomicSaved, parallel, progress
+	})
+	APIClient.Atomic, APIClient.Parallel, batch.Progress = atomic, 1, ioutil.Discard
+	importer = documentImporter{
+		apply: func(APIClient *types.APIClientOptions, document *manifest.Document, policy string) (string, error) {
+			target.mutex.Lock()
+			defer target.mutex.Unlock()
+			if target.fail[document.Name] {
+				return "", errors.New("import failed")
+			}
+			_, exists := target.objects[document.Name]
+			if exists && policy != conflict.Overwrite {
+				return "", conflict.ErrExists
+			}
+			target.objects[document.Name] = document.Field("description")
+			if exists {
+				return "updated", nil
+			}
+			return "created", nil
+		},
+		snapshot: func(APIClient *types.APIClientOptions, document *manifest.Document) (*manifest.Document, error) {
+			target.mutex.Lock()
+			defer target.mutex.Unlock()
+			target.snapshots++
+			if target.failSnapshot[document.Name] {
+				return nil, errors.New("not returned by the API")
+			}
+			description, exists := target.objects[document.Name]
+			if !exists {
+				return nil, nil
+			}
+			return manifest.New(document.Kind, document.Source, map[string]string{"name": document.Name, "description": description})
+		},
+		delete: func(APIClient *types.APIClientOptions, document *manifest.Document) (string, error) {
+			target.mutex.Lock()
+			defer target.mutex.Unlock()
+			delete(target.objects, document.Name)
+			return "deleted", nil
+		},
+	}
+}
+
+// testDocuments returns a Project, Variable and Endpoint document, imported in that order
+func testDocuments(t *testing.T) []*manifest.Document {
+	var documents []*manifest.Document
+	for _, kind := range []string{manifest.KindProject, manifest.KindVariable, manifest.KindEndpoint} {
+		document, err := manifest.New(kind, "test.yaml", map[string]string{"name": kind, "description": "new"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
+func TestImportDocumentsRollback(t *testing.T) {
+	target := &fakeTarget{
+		objects: map[string]string{manifest.KindProject: "old"},
+		fail:    map[string]bool{manifest.KindEndpoint: true},
+	}
+	useFakeTarget(t, target, true)
+
+	results := importDocuments(testDocuments(t), conflict.Overwrite)
+	for i, want := range []string{"rolled back", "rolled back", "failed"} {
+		if results[i].Result != want {
+			t.Errorf("%s: expected %q, got %q", results[i].Kind, want, results[i].Result)
+		}
+	}
+	// The overwritten Project is restored, and the created Variable deleted
+	if len(target.objects) != 1 || target.objects[manifest.KindProject] != "old" {
+		t.Errorf("unexpected objects after rollback %v", target.objects)
+	}
+}
+
+func TestImportDocumentsSnapshotFailure(t *testing.T) {
+	target := &fakeTarget{
+		objects:      map[string]string{manifest.KindProject: "old", manifest.KindVariable: "old"},
+		failSnapshot: map[string]bool{manifest.KindVariable: true},
+	}
+	useFakeTarget(t, target, true)
+
+	results := importDocuments(testDocuments(t), conflict.Overwrite)
+	for i, want := range []string{"rolled back", "failed", "not imported"} {
+		if results[i].Result != want {
+			t.Errorf("%s: expected %q, got %q", results[i].Kind, want, results[i].Result)
+		}
+	}
+	// An object that cannot be restored is not overwritten
+	if target.objects[manifest.KindProject] != "old" || target.objects[manifest.KindVariable] != "old" {
+		t.Errorf("unexpected objects after rollback %v", target.objects)
+	}
+	if _, ok := target.objects[manifest.KindEndpoint]; ok {
+		t.Error("expected the import to stop at the first failure")
+	}
+}
+
+func TestImportDocumentsWithoutAtomic(t *testing.T) {
+	target := &fakeTarget{
+		objects: map[string]string{manifest.KindProject: "old"},
+		fail:    map[string]bool{manifest.KindVariable: true},
+	}
+	useFakeTarget(t, target, false)
+
+	results := importDocuments(testDocuments(t), conflict.Overwrite)
+	for i, want := range []string{"updated", "failed", "created"} {
+		if results[i].Result != want {
+			t.Errorf("%s: expected %q, got %q", results[i].Kind, want, results[i].Result)
+		}
+	}
+	if target.snapshots != 0 {
+		t.Errorf("expected no snapshots without --atomic, got %d", target.snapshots)
+	}
+	if target.objects[manifest.KindProject] != "new" {
+		t.Errorf("unexpected objects %v", target.objects)
+	}
+}
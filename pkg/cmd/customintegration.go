@@ -9,7 +9,10 @@ import (
 	"strings"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/olekukonko/tablewriter"
@@ -50,13 +53,13 @@ Get by Project
 		if APIClient.Output == "json" {
 			helpers.PrettyPrint(response)
 		} else if APIClient.Output == "export" {
-			for _, c := range response {
-				if err := codestream.ExportCustomIntegration(*c, exportPath, APIClient.Force); err != nil {
-					log.Errorln("Unable to export Custom Integration: ", err)
-				} else {
-					log.Infoln("Exported Custom Integration:", c.Name)
-				}
+			names := make([]string, len(response))
+			for i, c := range response {
+				names[i] = c.Name
 			}
+			exportObjects(names, "Custom Integrations", func(client *types.APIClientOptions, i int) error {
+				return codestream.ExportCustomIntegration(client, *response[i], exportPath, client.Force)
+			})
 		} else {
 			// Print result table
 			table := tablewriter.NewWriter(os.Stdout)
@@ -80,6 +83,18 @@ var createCustomIntegrationCmd = &cobra.Command{
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		if importPath != "" {
+			customIntegration, err := codestream.ImportCustomIntegration(importPath)
+			if err != nil {
+				log.Fatalln("Unable to read the Custom Integration:", err)
+			}
+			document, err := manifest.New(manifest.KindCustomIntegration, importPath, customIntegration)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			reportImport(importDocuments([]*manifest.Document{document}, onConflict(conflict.Fail)))
+			return
+		}
 		createResponse, err := codestream.CreateCustomIntegration(APIClient, name, description, yaml, importPath)
 		if err != nil {
 			log.Fatalln("Unable to create Custom Integration:", err)
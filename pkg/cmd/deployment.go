@@ -92,5 +92,7 @@ func init() {
 	// Delete Deployment
 	deleteCmd.AddCommand(deleteDeploymentCmd)
 	deleteDeploymentCmd.Flags().StringVarP(&id, "id", "i", "", "Delete Deployment by ID")
-
+	// Completions
+	registerCompletions(getDeploymentCmd, "")
+	registerCompletions(deleteDeploymentCmd, "")
 }
@@ -0,0 +1,311 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sammcgeown/vra-cli/pkg/cmd/cloudassembly"
+	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/diff"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var diffFormat string
+
+// diffResult - the comparison of a local file with the live object
+type diffResult struct {
+	Name    string        `json:"name"`
+	File    string        `json:"file"`
+	Exists  bool          `json:"exists"`
+	Changes []diff.Change `json:"changes,omitempty"`
+	live    interface{}
+	local   interface{}
+}
+
+// customIntegrationIgnoredFields - server managed Custom Integration fields
+var customIntegrationIgnoredFields = []string{"id", "version", "status", "createdBy", "updatedBy", "createdAt", "updatedAt", "_link", "_updateTimeInMicros", "_createTimeInMicros"}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare local files with the objects on the server",
+	Long: `Compare local files with the objects on the server, showing what an update or apply would change.
+
+Both sides are normalised before comparing, so formatting, key order and server managed fields are ignored.
+The exit code is 1 when there are differences, for use in CI.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  func(cmd *cobra.Command, args []string) {},
+}
+
+// diffPipelineCmd represents the diff pipeline command
+var diffPipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Compare Pipeline YAML files with the server",
+	Long: `Compare Pipeline YAML files with the server
+
+# Compare a folder of exported Pipelines
+vra-cli diff pipeline --importPath ./pipelines`,
+	Run: func(cmd *cobra.Command, args []string) {
+		printDiffs(diffCodeStreamYaml("pipeline"))
+	},
+}
+
+// diffEndpointCmd represents the diff endpoint command
+var diffEndpointCmd = &cobra.Command{
+	Use:   "endpoint",
+	Short: "Compare Endpoint YAML files with the server",
+	Long: `Compare Endpoint YAML files with the server
+
+# Compare a folder of exported Endpoints
+vra-cli diff endpoint --importPath ./endpoints`,
+	Run: func(cmd *cobra.Command, args []string) {
+		printDiffs(diffCodeStreamYaml("endpoint"))
+	},
+}
+
+// diffVariableCmd represents the diff variable command
+var diffVariableCmd = &cobra.Command{
+	Use:   "variable",
+	Short: "Compare Variable YAML files with the server",
+	Long: `Compare Variable YAML files with the server. The values of SECRET and RESTRICTED variables are not compared.
+
+# Compare exported Variables
+vra-cli diff variable --importPath ./variables.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var results []*diffResult
+		for _, file := range helpers.GetFilePaths(importPath, ".yaml") {
+			for _, local := range codestream.ImportVariables(file) {
+				result := &diffResult{Name: local.Project + "/" + local.Name, File: file}
+				variables, err := codestream.GetVariable(APIClient, "", local.Name, local.Project, "")
+				if err != nil {
+					log.Fatalln("Unable to get Variable", local.Name, err)
+				}
+				ignore := []string{"kind"}
+				if local.Type == "SECRET" || local.Type == "RESTRICTED" {
+					ignore = append(ignore, "value")
+				}
+				result.local, _ = diff.NormaliseStruct(local, ignore...)
+				if len(variables) > 0 {
+					live := variables[0]
+					result.Exists = true
+					result.live, _ = diff.NormaliseStruct(types.VariableRequest{
+						Project:     live.Project,
+						Name:        live.Name,
+						Description: live.Description,
+						Type:        live.Type,
+						Value:       live.Value,
+					}, ignore...)
+				}
+				results = append(results, result)
+			}
+		}
+		printDiffs(results)
+	},
+}
+
+// diffCustomIntegrationCmd represents the diff customintegration command
+var diffCustomIntegrationCmd = &cobra.Command{
+	Use:   "customintegration",
+	Short: "Compare Custom Integration JSON files with the server",
+	Long: `Compare Custom Integration JSON files with the server
+
+# Compare exported Custom Integrations
+vra-cli diff customintegration --importPath ./customintegrations`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var results []*diffResult
+		for _, file := range helpers.GetFilePaths(importPath, ".json") {
+			local, err := codestream.ImportCustomIntegration(file)
+			if err != nil {
+				log.Fatalln("Unable to read", file, err)
+			}
+			result := &diffResult{Name: local.Name, File: file}
+			result.local = normaliseCustomIntegration(local)
+			customIntegrations, err := codestream.GetCustomIntegration(APIClient, "", local.Name)
+			if err != nil {
+				log.Fatalln("Unable to get Custom Integration", local.Name, err)
+			}
+			if len(customIntegrations) > 0 {
+				result.Exists = true
+				result.live = normaliseCustomIntegration(customIntegrations[0])
+			}
+			results = append(results, result)
+		}
+		printDiffs(results)
+	},
+}
+
+// diffCloudTemplateCmd represents the diff cloudtemplate command
+var diffCloudTemplateCmd = &cobra.Command{
+	Use:   "cloudtemplate",
+	Short: "Compare Cloud Template content files with the server",
+	Long: `Compare Cloud Template content files with the server. The Project and name are read from the
+"<Project> - <Name>.yaml" file names written by get cloudtemplate --exportPath, unless --project and --name are set.
+
+# Compare exported Cloud Templates
+vra-cli diff cloudtemplate --importPath ./cloudtemplates`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var results []*diffResult
+		for _, file := range helpers.GetFilePaths(importPath, ".yaml") {
+			templateProject, templateName := projectName, name
+			if templateProject == "" || templateName == "" {
+				parts := strings.SplitN(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), " - ", 2)
+				if len(parts) != 2 {
+					log.Fatalln("Unable to find the Project and name of", file, "- use --project and --name")
+				}
+				templateProject, templateName = parts[0], parts[1]
+			}
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				log.Fatalln("Unable to read", file, err)
+			}
+			result := &diffResult{Name: templateProject + "/" + templateName, File: file}
+			if result.local, err = diff.NormaliseYAML(content); err != nil {
+				log.Fatalln("Unable to parse", file, err)
+			}
+			templates, err := cloudassembly.GetCloudTemplate(APIClient, "", templateName, templateProject)
+			if err != nil {
+				log.Fatalln("Unable to get Cloud Template", templateName, err)
+			}
+			for _, t := range templates {
+				if t.Name == templateName {
+					result.Exists = true
+					if result.live, err = diff.NormaliseYAML([]byte(t.Content)); err != nil {
+						log.Fatalln("Unable to parse the content of Cloud Template", templateName, err)
+					}
+				}
+			}
+			results = append(results, result)
+		}
+		printDiffs(results)
+	},
+}
+
+// diffCodeStreamYaml compares Pipeline or Endpoint YAML files with the Code Stream export of the live object
+func diffCodeStreamYaml(object string) []*diffResult {
+	var results []*diffResult
+	for _, file := range helpers.GetFilePaths(importPath, ".yaml") {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalln("Unable to read", file, err)
+		}
+		local, err := diff.NormaliseYAML(content)
+		if err != nil {
+			log.Fatalln("Unable to parse", file, err)
+		}
+		fields, _ := local.(map[string]interface{})
+		objectName, objectProject := fmt.Sprint(fields["name"]), fmt.Sprint(fields["project"])
+		result := &diffResult{Name: objectProject + "/" + objectName, File: file, local: local}
+
+		var exists bool
+		if object == "pipeline" {
+			pipelines, err := codestream.GetPipeline(APIClient, "", objectName, objectProject, "")
+			if err != nil {
+				log.Fatalln("Unable to get Pipeline", objectName, err)
+			}
+			exists = len(pipelines) > 0
+		} else {
+			endpoints, err := codestream.GetEndpoint(APIClient, "", objectName, objectProject, "", "")
+			if err != nil {
+				log.Fatalln("Unable to get Endpoint", objectName, err)
+			}
+			exists = len(endpoints) > 0
+		}
+		if exists {
+			live, err := codestream.GetExportYaml(APIClient, objectName, objectProject, object)
+			if err != nil {
+				log.Fatalln("Unable to export", object, objectName, err)
+			}
+			result.Exists = true
+			if result.live, err = diff.NormaliseYAML(live); err != nil {
+				log.Fatalln("Unable to parse the export of", object, objectName, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// normaliseCustomIntegration normalises a Custom Integration, comparing its YAML as a document rather than a string
+func normaliseCustomIntegration(ci *types.CustomIntegration) interface{} {
+	normalised, _ := diff.NormaliseStruct(ci, customIntegrationIgnoredFields...)
+	if m, ok := normalised.(map[string]interface{}); ok && ci.Yaml != "" {
+		if content, err := diff.NormaliseYAML([]byte(ci.Yaml)); err == nil {
+			m["yaml"] = content
+		}
+	}
+	return normalised
+}
+
+// printDiffs prints the differences, and exits with status 1 if there are any
+func printDiffs(results []*diffResult) {
+	if diffFormat != "unified" && diffFormat != "structured" {
+		log.Fatalln("--format must be unified or structured")
+	}
+	if len(results) == 0 {
+		log.Warnln("No files were found in", importPath)
+		return
+	}
+	differences := 0
+	for _, r := range results {
+		if !r.Exists {
+			r.Changes = []diff.Change{{Path: r.Name, Type: "added", To: "(new object)"}}
+		} else {
+			r.Changes = diff.Structured(r.live, r.local)
+		}
+		if len(r.Changes) > 0 {
+			differences++
+		}
+	}
+
+	if APIClient.Output == "json" {
+		helpers.PrettyPrint(results)
+	} else {
+		for _, r := range results {
+			switch {
+			case len(r.Changes) == 0:
+				log.Debugln(r.Name, "is up to date")
+			case !r.Exists:
+				fmt.Println("+++", r.File, "(not found on the server, will be created)")
+			case diffFormat == "structured":
+				fmt.Println("***", r.Name, "("+r.File+")")
+				for _, c := range r.Changes {
+					fmt.Println(c)
+				}
+			default:
+				fmt.Print(diff.Unified(r.live, r.local, "server/"+r.Name, r.File))
+			}
+		}
+	}
+
+	if differences > 0 {
+		log.Infoln(differences, "of", len(results), "objects differ from the server")
+		if !shellSession {
+			tracing.Shutdown()
+			os.Exit(1)
+		}
+	} else {
+		log.Infoln("No differences found")
+	}
+}
+
+func init() {
+	diffCmd.PersistentFlags().StringVar(&diffFormat, "format", "unified", "Diff format - unified or structured")
+	for _, c := range []*cobra.Command{diffPipelineCmd, diffEndpointCmd, diffVariableCmd, diffCustomIntegrationCmd, diffCloudTemplateCmd} {
+		diffCmd.AddCommand(c)
+		c.Flags().StringVarP(&importPath, "importPath", "", "", "File, or folder of files, to compare with the server")
+		c.MarkFlagRequired("importPath")
+	}
+	diffCloudTemplateCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the Cloud Template (defaults to the file name)")
+	diffCloudTemplateCmd.Flags().StringVarP(&projectName, "project", "p", "", "Project of the Cloud Template (defaults to the file name)")
+}
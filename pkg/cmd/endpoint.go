@@ -5,12 +5,12 @@ SPDX-License-Identifier: BSD-2-Clause
 package cmd
 
 import (
-	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/olekukonko/tablewriter"
@@ -36,14 +36,13 @@ var getEndpointCmd = &cobra.Command{
 		if APIClient.Output == "json" {
 			helpers.PrettyPrint(response)
 		} else if APIClient.Output == "export" {
-			for _, c := range response {
-				err := codestream.ExportYaml(APIClient, c.ID, c.Name, c.Project, exportPath, "endpoints")
-				if err != nil {
-					log.Infoln("Endpoint", c.Name, "export failed: ", err)
-				} else {
-					log.Infoln("Endpoint", c.Name, "exported successfully")
-				}
+			names := make([]string, len(response))
+			for i, c := range response {
+				names[i] = c.Name
 			}
+			exportObjects(names, "Endpoints", func(client *types.APIClientOptions, i int) error {
+				return codestream.ExportYaml(client, response[i].ID, response[i].Name, response[i].Project, exportPath, "endpoints")
+			})
 		} else {
 			table := tablewriter.NewWriter(os.Stdout)
 			table.SetHeader([]string{"ID", "Name", "Project", "Type", "Description"})
@@ -63,6 +62,9 @@ var createEndpointCmd = &cobra.Command{
 	
 	Create from YAML
 	  vra-cli create endpoint --importPath "/Users/sammcgeown/Desktop/endpoint.yaml"
+
+	Create from a template, with the values for an environment (see vra-cli render)
+	  vra-cli create endpoint --importPath endpoint.yaml.tmpl --values prod.yaml
 	`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		return nil
@@ -74,15 +76,7 @@ var createEndpointCmd = &cobra.Command{
 			if len(yamlFilePaths) == 0 {
 				log.Warnln("No YAML files were found in", importPath)
 			}
-			for _, yamlFilePath := range yamlFilePaths {
-				yamlFileName := filepath.Base(yamlFilePath)
-				err := codestream.ImportYaml(APIClient, yamlFilePath, "create", projectName, "endpoint")
-				if err != nil {
-					log.Warnln("Failed to import", yamlFilePath, "as Endpoint", err)
-				} else {
-					fmt.Println("Imported", yamlFileName, "successfully - Endpoint created.")
-				}
-			}
+			reportImport(importFiles(yamlFilePaths, projectName, onConflict(conflict.Fail)))
 		}
 	},
 }
@@ -105,15 +99,7 @@ var updateEndpointCmd = &cobra.Command{
 			if len(yamlFilePaths) == 0 {
 				log.Warnln("No YAML files were found in", importPath)
 			}
-			for _, yamlFilePath := range yamlFilePaths {
-				yamlFileName := filepath.Base(yamlFilePath)
-				err := codestream.ImportYaml(APIClient, yamlFilePath, "apply", "", "endpoint")
-				if err != nil {
-					log.Warnln("Failed to import", yamlFilePath, "as Endpoint", err)
-				} else {
-					fmt.Println("Imported", yamlFileName, "successfully - Endpoint updated.")
-				}
-			}
+			reportImport(importFiles(yamlFilePaths, "", onConflict(conflict.Overwrite)))
 		}
 	},
 }
@@ -148,15 +134,11 @@ vra-cli delete endpoint --project "My Project"
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if name != "" {
-			response, err := codestream.GetEndpoint(APIClient, id, name, projectName, typename, exportPath)
+			endpointID, err := codestream.GetEndpointID(APIClient, name, projectName)
 			if err != nil {
 				log.Fatalln(err)
 			}
-			// return first element of map[string]
-			for _, c := range response {
-				id = c.ID
-				break
-			}
+			id = endpointID
 		}
 
 		if id != "" {
@@ -170,8 +152,9 @@ vra-cli delete endpoint --project "My Project"
 			response, err := codestream.DeleteEndpointByProject(APIClient, projectName)
 			if err != nil {
 				log.Errorln("Unable to delete Endpoint: ", err)
+			} else {
+				reportDeleted(response, "Endpoints")
 			}
-			log.Infoln(len(response), "Endpoints deleted")
 
 		}
 
@@ -188,16 +171,20 @@ func init() {
 	// Create
 	createCmd.AddCommand(createEndpointCmd)
 	createEndpointCmd.Flags().StringVarP(&importPath, "importPath", "c", "", "YAML configuration file to import")
+	createEndpointCmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "Values file for .tmpl templates (can be repeated, later files take precedence)")
 	createEndpointCmd.Flags().StringVarP(&projectName, "project", "p", "", "Manually specify the Project in which to create the Endpoint (overrides YAML)")
 	createEndpointCmd.MarkFlagRequired("importPath")
 	// Update
 	updateCmd.AddCommand(updateEndpointCmd)
 	updateEndpointCmd.Flags().StringVarP(&importPath, "importPath", "c", "", "YAML configuration file to import")
+	updateEndpointCmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "Values file for .tmpl templates (can be repeated, later files take precedence)")
 	updateEndpointCmd.MarkFlagRequired("importPath")
 	// Delete
 	deleteCmd.AddCommand(deleteEndpointCmd)
 	deleteEndpointCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the Endpoint to delete")
 	deleteEndpointCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the Endpoint to delete")
 	deleteEndpointCmd.Flags().StringVarP(&projectName, "project", "p", "", "Delete Endpoints by Project")
-
+	// Completions
+	registerCompletions(getEndpointCmd, "endpoint")
+	registerCompletions(deleteEndpointCmd, "endpoint")
 }
@@ -5,11 +5,22 @@ SPDX-License-Identifier: BSD-2-Clause
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/pipelineexecution"
+	"github.com/sammcgeown/vra-cli/pkg/util/pipelineinput"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/olekukonko/tablewriter"
@@ -18,6 +29,19 @@ import (
 
 var nested, rollback bool
 var inputs, comments string
+var (
+	executionWatch       bool
+	executionInterval    time.Duration
+	executionWait        bool
+	executionTimeout     time.Duration
+	executionOutputVars  []string
+	executionOutputsJSON string
+	executionInputsFile  string
+	executionInputs      []string
+	executionReason      string
+	executionRerunFailed bool
+	executionSince       string
+)
 
 // getExecutionCmd represents the executions command
 var getExecutionCmd = &cobra.Command{
@@ -30,8 +54,26 @@ vra-cli get execution --status FAILED
 # Get an execution by ID:
 vra-cli get execution --id bb3f6aff-311a-45fe-8081-5845a529068d
 # Get Failed executions in Project "Field Demo" with the name "Learn Code Stream"
-vra-cli get execution --status FAILED --project "Field Demo" --name "Learn Code Stream"`,
+vra-cli get execution --status FAILED --project "Field Demo" --name "Learn Code Stream"
+# Follow an execution until it ends, printing stage and task status changes and new log lines. The exit
+# status is 0 if it completed, 1 if it failed, 2 if it was canceled and 3 if it was rolled back, or 5 if
+# the execution could not be read 3 times in a row.
+vra-cli get execution --id bb3f6aff-311a-45fe-8081-5845a529068d --watch`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if executionWatch && id == "" {
+			return errors.New("--watch requires --id")
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if executionWatch {
+			execution := watchExecution(id, 0, progressWriter())
+			if APIClient.Output == "json" {
+				helpers.PrettyPrint(execution)
+			}
+			exitExecution(execution)
+			return
+		}
 
 		response, err := codestream.GetExecution(APIClient, id, projectName, status, name, nested, rollback)
 		if err != nil {
@@ -78,7 +120,7 @@ var delExecutionCmd = &cobra.Command{
 			if err != nil {
 				log.Errorln("Unable to delete executions:", err)
 			} else {
-				log.Infoln(len(response), "Executions deleted")
+				reportDeleted(response, "Executions")
 			}
 		}
 	},
@@ -88,20 +130,448 @@ var delExecutionCmd = &cobra.Command{
 var createExecutionCmd = &cobra.Command{
 	Use:   "execution",
 	Short: "Create an Execution",
-	Long: `Create an Execution with a specific pipeline ID and form payload.
-	
-	`,
+	Long: `Create an Execution of a Pipeline, by ID or by name and Project.
+
+Inputs are read from --inputs-file (JSON, YAML or a dotenv KEY=value file), then --inputs (JSON) and then
+each --input key=value, with later values taking precedence. Inputs the Pipeline does not declare are
+rejected, and inputs without a default value (null) that are not given are prompted for. Inputs with an
+empty default value are optional.
+
+With --wait, the command follows the Execution until it ends, and exits with a status that shows how it
+ended: 0 if it completed, 1 if it failed, 2 if it was canceled and 3 if it was rolled back, 4 if it was
+still running at the --timeout, or 5 if the Execution could not be read 3 times in a row. The outputs
+of the Execution can then be printed with --output-var, or written to a file with --outputs-json.
+
+# Run a pipeline by name, with inputs from a file and an override
+vra-cli create execution --name "Deploy" --project "Field Demo" --inputs-file prod.env -i branch=main
+
+# Run a pipeline in CI and use its outputs
+URL=$(vra-cli create execution --id 71dcc4aa-fa43-4e66-a9c6-4d1d6da2a4ab --inputs '{"branch":"main"}' --wait --timeout 30m --output-var url)
+
+# Write the outputs of the execution to a file
+vra-cli create execution --id 71dcc4aa-fa43-4e66-a9c6-4d1d6da2a4ab --inputs '{}' --wait --outputs-json outputs.json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if id == "" && name == "" {
+			return errors.New("--id or --name is required")
+		}
+		if !executionWait && (len(executionOutputVars) > 0 || executionOutputsJSON != "" || executionTimeout != 0) {
+			return errors.New("--timeout, --output-var and --outputs-json require --wait")
+		}
+		_, err := pipelineinput.ParseOverrides(executionInputs)
+		return err
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		pipeline, err := executionPipeline()
+		if err != nil {
+			log.Fatalln("Unable to find pipeline:", err)
+		}
+		pipelineInputs, err := executionInputValues(pipeline)
+		if err != nil {
+			log.Fatalln("Unable to get inputs:", err)
+		}
+		inputBytes, err := json.Marshal(pipelineInputs)
+		if err != nil {
+			log.Fatalln(err)
+		}
 
-		response, err := codestream.CreateExecution(APIClient, id, inputs, comments)
+		response, err := codestream.CreateExecution(APIClient, pipeline.ID, string(inputBytes), comments)
 		if err != nil {
-			log.Errorln("Unable to create execution:", err)
+			log.Fatalln("Unable to create execution:", err)
 		}
 		log.Infoln("Execution", response.ExecutionID, "created")
+		if !executionWait {
+			return
+		}
 
+		execution := watchExecution(response.ExecutionID, executionTimeout, progressWriter())
+		if APIClient.Output == "json" && len(executionOutputVars) == 0 {
+			helpers.PrettyPrint(execution)
+		}
+		outputErr := writeExecutionOutputs(os.Stdout, execution)
+		exitExecution(execution)
+		if outputErr != nil {
+			log.Fatalln("Unable to get execution outputs:", outputErr)
+		}
 	},
 }
 
+// cancelExecutionCmd represents the cancel execution command
+var cancelExecutionCmd = &cobra.Command{
+	Use:   "execution",
+	Short: "Cancel an Execution",
+	Long: `Cancel a running Execution with a specific Execution ID
+
+# Cancel an execution
+vra-cli cancel execution --id bb3f6aff-311a-45fe-8081-5845a529068d --reason "Deploying a fix"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		execution, err := getExecutionByID(id)
+		if err != nil {
+			log.Fatalln("Unable to get execution:", err)
+		}
+		if pipelineexecution.Finished(execution.Status) {
+			log.Fatalln("Execution", execution.Name+"#"+fmt.Sprint(execution.Index), "has already ended with status", execution.Status)
+		}
+		if err := codestream.CancelExecution(APIClient, id, executionReason); err != nil {
+			log.Fatalln("Unable to cancel execution:", err)
+		}
+		log.Infoln("Execution", execution.Name+"#"+fmt.Sprint(execution.Index), "canceled")
+	},
+}
+
+// resumeExecutionCmd represents the resume execution command
+var resumeExecutionCmd = &cobra.Command{
+	Use:   "execution",
+	Short: "Resume an Execution",
+	Long: `Resume a paused Execution with a specific Execution ID
+
+# Resume an execution
+vra-cli resume execution --id bb3f6aff-311a-45fe-8081-5845a529068d`,
+	Run: func(cmd *cobra.Command, args []string) {
+		execution, err := getExecutionByID(id)
+		if err != nil {
+			log.Fatalln("Unable to get execution:", err)
+		}
+		if !strings.EqualFold(execution.Status, "PAUSED") {
+			log.Fatalln("Execution", execution.Name+"#"+fmt.Sprint(execution.Index), "is", execution.Status+", only PAUSED executions can be resumed")
+		}
+		if err := codestream.ResumeExecution(APIClient, id); err != nil {
+			log.Fatalln("Unable to resume execution:", err)
+		}
+		log.Infoln("Execution", execution.Name+"#"+fmt.Sprint(execution.Index), "resumed")
+	},
+}
+
+// rerunExecutionCmd represents the rerun execution command
+var rerunExecutionCmd = &cobra.Command{
+	Use:   "execution",
+	Short: "Rerun an Execution",
+	Long: `Run the Pipeline of an Execution again, with the inputs and comments of the Execution. Inputs can be
+overridden with --inputs-file, --inputs and --input, and comments with --comments.
+
+With --rerun-failed, every FAILED Execution matching --project, --name and --since is run again. Nested
+and rollback Executions are not rerun, as they are run by the Executions that failed.
+
+# Rerun an execution with a different branch
+vra-cli rerun execution --id bb3f6aff-311a-45fe-8081-5845a529068d -i branch=hotfix
+
+# Rerun every execution in a Project that failed since yesterday
+vra-cli rerun execution --rerun-failed --project "Field Demo" --since yesterday`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if (id == "") == !executionRerunFailed {
+			return errors.New("either --id or --rerun-failed is required")
+		}
+		if executionSince != "" && !executionRerunFailed {
+			return errors.New("--since requires --rerun-failed")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		overrides, err := executionInputOverrides()
+		if err != nil {
+			log.Fatalln("Unable to get inputs:", err)
+		}
+		if id != "" {
+			response, err := codestream.RerunExecution(APIClient, id, overrides, comments)
+			if err != nil {
+				log.Fatalln("Unable to rerun execution:", err)
+			}
+			log.Infoln("Execution", response.ExecutionID, "created")
+			return
+		}
+
+		executions, err := failedExecutions()
+		if err != nil {
+			log.Fatalln("Unable to get executions:", err)
+		}
+		if len(executions) == 0 {
+			log.Infoln("No failed executions found")
+			return
+		}
+		if APIClient.DryRun {
+			for _, execution := range executions {
+				log.Infoln("[dry-run] Would rerun Execution", execution.Name+"#"+fmt.Sprint(execution.Index), "("+execution.ID+")")
+			}
+		} else if !APIClient.Confirm && !helpers.AskForConfirmation("This will rerun "+fmt.Sprint(len(executions))+" executions, are you sure?") {
+			return
+		}
+		names := make([]string, len(executions))
+		for i, execution := range executions {
+			names[i] = execution.Name + "#" + fmt.Sprint(execution.Index)
+		}
+		clients := workerClients(len(executions))
+		results := batch.Run("Rerunning", names, APIClient.Parallel, func(worker, i int) (string, error) {
+			if _, err := codestream.RerunExecution(clients[worker], executions[i].ID, overrides, comments); err != nil {
+				return "", err
+			}
+			if APIClient.DryRun {
+				return "would rerun", nil
+			}
+			return "rerun", nil
+		})
+		batch.Print(os.Stdout, results, APIClient.Output)
+		if failed := batch.Failures(results); failed > 0 {
+			log.Errorln(failed, "of", len(results), "executions could not be rerun")
+		}
+	},
+}
+
+// getExecutionByID returns an Execution by ID
+func getExecutionByID(id string) (*types.Executions, error) {
+	executions, err := codestream.GetExecution(APIClient, id, "", "", "", false, false)
+	if err != nil {
+		return nil, err
+	}
+	return executions[0], nil
+}
+
+// failedExecutions returns the FAILED Executions matching --project, --name and --since, except nested
+// and rollback Executions
+func failedExecutions() ([]*types.Executions, error) {
+	var since time.Time
+	if executionSince != "" {
+		var err error
+		if since, err = parseSince(executionSince, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	executions, err := codestream.GetExecutionsSince(APIClient, projectName, pipelineexecution.Failed, name, since)
+	if err != nil {
+		return nil, err
+	}
+	var failed []*types.Executions
+	for _, execution := range executions {
+		if execution.Nested || execution.Rollback {
+			continue
+		}
+		failed = append(failed, execution)
+	}
+	return failed, nil
+}
+
+// parseSince returns the time described by --since - a duration before now (e.g. 24h), today, yesterday,
+// a date (2006-01-02) or a time (RFC 3339)
+func parseSince(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("--since %q is not a duration, today, yesterday, a date or an RFC 3339 time", value)
+}
+
+// executionPipeline returns the Pipeline to execute, by --id or by --name and --project
+func executionPipeline() (*types.Pipeline, error) {
+	pipelines, err := codestream.GetPipeline(APIClient, id, name, projectName, "")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(pipelines) == 0 && id != "":
+		return nil, fmt.Errorf("there is no Pipeline with ID %s", id)
+	case len(pipelines) == 0:
+		return nil, fmt.Errorf("there is no Pipeline named %s", name)
+	case len(pipelines) > 1:
+		var projects []string
+		for _, p := range pipelines {
+			projects = append(projects, p.Project)
+		}
+		return nil, fmt.Errorf("there are Pipelines named %s in Projects %s, use --project to choose one", name, strings.Join(projects, ", "))
+	}
+	return pipelines[0], nil
+}
+
+// executionInputOverrides returns the inputs from --inputs-file, --inputs and --input, with later values taking precedence
+func executionInputOverrides() (map[string]string, error) {
+	var sets []map[string]string
+	if executionInputsFile != "" {
+		fileInputs, err := pipelineinput.Load(executionInputsFile)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, fileInputs)
+	}
+	if inputs != "" {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(inputs), &values); err != nil {
+			return nil, fmt.Errorf("--inputs is not a JSON object: %w", err)
+		}
+		jsonInputs, err := pipelineinput.Strings(values)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, jsonInputs)
+	}
+	overrides, err := pipelineinput.ParseOverrides(executionInputs)
+	if err != nil {
+		return nil, err
+	}
+	return pipelineinput.Merge(append(sets, overrides)...), nil
+}
+
+// executionInputValues returns the inputs for an Execution of the Pipeline, validated against the Pipeline's
+// inputs. Inputs without a default value (null) that are not given are prompted for.
+func executionInputValues(pipeline *types.Pipeline) (map[string]string, error) {
+	values, err := executionInputOverrides()
+	if err != nil {
+		return nil, err
+	}
+	declared, _ := pipeline.Input.(map[string]interface{})
+	if err := pipelineinput.Validate(values, declared); err != nil {
+		return nil, err
+	}
+	missing := pipelineinput.Missing(values, declared)
+	if len(missing) > 0 && helpers.IsInputFromPipe() {
+		log.Warnln("No values were given for inputs", strings.Join(missing, ", "))
+		return values, nil
+	}
+	for _, input := range missing {
+		values[input] = helpers.AskForInput(input)
+	}
+	return values, nil
+}
+
+// executionWatcher prints the changes to an Execution between polls
+type executionWatcher struct {
+	out      io.Writer
+	statuses map[string]string // Status by Execution, stage or stage.task
+	logs     map[int]int       // Log lines printed by workspace result
+}
+
+// progressWriter returns where to print the progress of an Execution - stdout, or stderr when stdout
+// is used for the final state of the Execution or its outputs
+func progressWriter() io.Writer {
+	if APIClient.Output == "json" || len(executionOutputVars) > 0 {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// watchExecution follows an Execution until it ends or timeout (if not zero) passes, printing its
+// progress to out, and returns its final state
+func watchExecution(id string, timeout time.Duration, out io.Writer) *types.Executions {
+	w := &executionWatcher{out: out, statuses: make(map[string]string), logs: make(map[int]int)}
+	execution, err := codestream.WatchExecution(APIClient, id, executionInterval, timeout, w.update)
+	if err != nil {
+		log.Errorln("Unable to watch execution:", err)
+		if errors.Is(err, pipelineexecution.ErrTimeout) {
+			exitWith(pipelineexecution.ExitTimeout)
+		}
+		exitWith(pipelineexecution.ExitUnavailable)
+	}
+	return execution
+}
+
+// writeExecutionOutputs prints the --output-var outputs of an Execution to out and writes all of its outputs
+// to --outputs-json, returning an error if an output does not exist
+func writeExecutionOutputs(out io.Writer, execution *types.Executions) error {
+	if executionOutputsJSON != "" {
+		outputs, err := json.MarshalIndent(execution.Output, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(executionOutputsJSON, append(outputs, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	var missing []string
+	for _, name := range executionOutputVars {
+		value, ok := pipelineexecution.Output(execution, name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		text, ok := value.(string)
+		if !ok {
+			valueBytes, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			text = string(valueBytes)
+		}
+		if len(executionOutputVars) == 1 {
+			fmt.Fprintln(out, text)
+		} else {
+			fmt.Fprintln(out, name+"="+text)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the execution has no output %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (w *executionWatcher) update(execution *types.Executions) {
+	w.status("", execution.Name+"#"+fmt.Sprint(execution.Index), execution.Status, execution.StatusMessage)
+	stages, err := pipelineexecution.Stages(execution)
+	if err != nil {
+		log.Debugln("Unable to read execution stages:", err)
+	}
+	for _, stage := range stages {
+		w.status(stage.Name, stage.Name, stage.Status, stage.StatusMessage)
+		for _, name := range pipelineexecution.TaskNames(stage) {
+			task := stage.Tasks[name]
+			w.status(stage.Name+"."+name, stage.Name+" > "+name, task.Status, task.StatusMessage)
+		}
+	}
+	for i, result := range execution.WorkspaceResults {
+		if w.logs[i] > len(result.Logs) { // The logs were truncated
+			w.logs[i] = 0
+		}
+		for _, line := range result.Logs[w.logs[i]:] {
+			fmt.Fprintf(w.out, "  %s | %s\n", result.Step, line)
+		}
+		w.logs[i] = len(result.Logs)
+	}
+}
+
+// status prints the status of the Execution, a stage or a task if it has changed
+func (w *executionWatcher) status(key, name, status, message string) {
+	if status == "" || w.statuses[key] == status {
+		return
+	}
+	w.statuses[key] = status
+	line := time.Now().Format("15:04:05") + " " + name + " " + status
+	if message != "" {
+		line += " - " + message
+	}
+	fmt.Fprintln(w.out, line)
+}
+
+// exitExecution logs how an Execution ended, and exits with its status code if it did not complete
+func exitExecution(execution *types.Executions) {
+	name := execution.Name + "#" + fmt.Sprint(execution.Index)
+	code := pipelineexecution.ExitCode(execution.Status)
+	if code == 0 {
+		log.Infoln("Execution", name, execution.Status)
+		return
+	}
+	log.Errorln("Execution", name, execution.Status+":", execution.StatusMessage)
+	if !shellSession {
+		tracing.Shutdown()
+		os.Exit(code)
+	}
+}
+
+// exitWith ends the command with an exit code, or only the command when it is run in the shell
+func exitWith(code int) {
+	if shellSession {
+		panic(shellExit{code})
+	}
+	tracing.Shutdown()
+	os.Exit(code)
+}
+
 func init() {
 	// Get
 	getCmd.AddCommand(getExecutionCmd)
@@ -111,6 +581,9 @@ func init() {
 	getExecutionCmd.Flags().StringVarP(&projectName, "project", "p", "", "Filter executions by Project")
 	getExecutionCmd.Flags().BoolVarP(&nested, "nested", "", false, "Include nested executions")
 	getExecutionCmd.Flags().BoolVarP(&rollback, "rollback", "", false, "Include rollback executions")
+	getExecutionCmd.Flags().BoolVarP(&executionWatch, "watch", "w", false, "Follow the execution until it ends, printing stage and task status changes and new log lines")
+	getExecutionCmd.Flags().BoolVar(&executionWatch, "follow", false, "Alias for --watch")
+	getExecutionCmd.Flags().DurationVar(&executionInterval, "interval", 5*time.Second, "How often to check the execution with --watch")
 	// Delete
 	deleteCmd.AddCommand(delExecutionCmd)
 	delExecutionCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the pipeline to delete executions for")
@@ -121,9 +594,42 @@ func init() {
 	delExecutionCmd.Flags().BoolVarP(&rollback, "rollback", "", false, "Delete rollback executions")
 	// Create
 	createCmd.AddCommand(createExecutionCmd)
-	createExecutionCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the pipeline to execute")
+	createExecutionCmd.Flags().StringVar(&id, "id", "", "ID of the pipeline to execute")
+	createExecutionCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the pipeline to execute")
+	createExecutionCmd.Flags().StringVarP(&projectName, "project", "p", "", "Project of the pipeline to execute, with --name")
 	createExecutionCmd.Flags().StringVarP(&inputs, "inputs", "", "", "JSON form inputs")
-	createExecutionCmd.Flags().StringVarP(&importPath, "importPath", "", "", "JSON input file")
+	createExecutionCmd.Flags().StringVar(&executionInputsFile, "inputs-file", "", "JSON, YAML or dotenv (.env) file of inputs")
+	createExecutionCmd.Flags().StringArrayVarP(&executionInputs, "input", "i", nil, "Input as key=value (can be repeated, overrides --inputs-file and --inputs)")
+	createExecutionCmd.Flags().StringVar(&executionInputsFile, "importPath", "", "JSON input file")
+	createExecutionCmd.Flags().MarkDeprecated("importPath", "use --inputs-file instead")
 	createExecutionCmd.Flags().StringVarP(&comments, "comments", "", "", "Execution comments")
-	createExecutionCmd.MarkFlagRequired("id")
+	createExecutionCmd.Flags().BoolVar(&executionWait, "wait", false, "Wait for the execution to end, printing stage and task status changes and new log lines")
+	createExecutionCmd.Flags().DurationVar(&executionTimeout, "timeout", 0, "How long to wait for the execution with --wait (0 for no limit)")
+	createExecutionCmd.Flags().DurationVar(&executionInterval, "interval", 5*time.Second, "How often to check the execution with --wait")
+	createExecutionCmd.Flags().StringArrayVar(&executionOutputVars, "output-var", nil, "Print an output of the execution with --wait (can be repeated, several outputs are printed as name=value)")
+	createExecutionCmd.Flags().StringVar(&executionOutputsJSON, "outputs-json", "", "Write the outputs of the execution to a JSON file with --wait")
+	// Cancel
+	cancelCmd.AddCommand(cancelExecutionCmd)
+	cancelExecutionCmd.Flags().StringVar(&id, "id", "", "ID of the execution to cancel")
+	cancelExecutionCmd.Flags().StringVar(&executionReason, "reason", "", "Reason for canceling the execution")
+	cancelExecutionCmd.MarkFlagRequired("id")
+	// Resume
+	resumeCmd.AddCommand(resumeExecutionCmd)
+	resumeExecutionCmd.Flags().StringVar(&id, "id", "", "ID of the execution to resume")
+	resumeExecutionCmd.MarkFlagRequired("id")
+	// Rerun
+	rerunCmd.AddCommand(rerunExecutionCmd)
+	rerunExecutionCmd.Flags().StringVar(&id, "id", "", "ID of the execution to rerun")
+	rerunExecutionCmd.Flags().BoolVar(&executionRerunFailed, "rerun-failed", false, "Rerun every FAILED execution matching --project, --name and --since")
+	rerunExecutionCmd.Flags().StringVarP(&projectName, "project", "p", "", "Rerun failed executions in a Project, with --rerun-failed")
+	rerunExecutionCmd.Flags().StringVarP(&name, "name", "n", "", "Rerun failed executions of a pipeline, with --rerun-failed")
+	rerunExecutionCmd.Flags().StringVar(&executionSince, "since", "", "Rerun failed executions created since a duration ago (e.g. 24h), today, yesterday, a date or a time, with --rerun-failed")
+	rerunExecutionCmd.Flags().StringVarP(&inputs, "inputs", "", "", "JSON form inputs, overriding the inputs of the execution")
+	rerunExecutionCmd.Flags().StringVar(&executionInputsFile, "inputs-file", "", "JSON, YAML or dotenv (.env) file of inputs, overriding the inputs of the execution")
+	rerunExecutionCmd.Flags().StringArrayVarP(&executionInputs, "input", "i", nil, "Input as key=value, overriding the inputs of the execution (can be repeated)")
+	rerunExecutionCmd.Flags().StringVarP(&comments, "comments", "", "", "Execution comments (default is the comments of the execution)")
+	// Completions
+	registerCompletions(getExecutionCmd, "")
+	registerCompletions(delExecutionCmd, "")
+	registerCompletions(rerunExecutionCmd, "")
 }
@@ -0,0 +1,106 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+)
+
+func TestWriteExecutionOutputs(t *testing.T) {
+	outputVars, outputsJSON := executionOutputVars, executionOutputsJSON
+	t.Cleanup(func() { executionOutputVars, executionOutputsJSON = outputVars, outputsJSON })
+	execution := &types.Executions{Output: map[string]interface{}{
+		"url":      "https://example.com",
+		"replicas": float64(3),
+		"tags":     []interface{}{"a", "b"},
+		"empty":    "",
+	}}
+
+	tests := []struct {
+		name       string
+		outputVars []string
+		want       string
+		err        string
+	}{
+		{name: "none", outputVars: nil, want: ""},
+		{name: "single", outputVars: []string{"url"}, want: "https://example.com\n"},
+		{name: "single non-string", outputVars: []string{"tags"}, want: `["a","b"]` + "\n"},
+		{name: "single empty", outputVars: []string{"empty"}, want: "\n"},
+		{name: "multiple", outputVars: []string{"url", "replicas", "tags"}, want: "url=https://example.com\nreplicas=3\ntags=[\"a\",\"b\"]\n"},
+		{name: "missing", outputVars: []string{"url", "version", "branch"}, want: "url=https://example.com\n", err: "the execution has no output version, branch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executionOutputVars, executionOutputsJSON = tt.outputVars, ""
+			var out bytes.Buffer
+			err := writeExecutionOutputs(&out, execution)
+			if tt.err == "" && err != nil {
+				t.Fatal(err)
+			} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, out.String())
+			}
+		})
+	}
+
+	// Every output is written to --outputs-json
+	executionOutputVars, executionOutputsJSON = nil, filepath.Join(t.TempDir(), "outputs.json")
+	if err := writeExecutionOutputs(ioutil.Discard, execution); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(executionOutputsJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"empty\": \"\",\n  \"replicas\": 3,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"url\": \"https://example.com\"\n}\n"
+	if string(content) != want {
+		t.Errorf("expected %q, got %q", want, content)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2021, 6, 15, 14, 30, 0, 0, location)
+
+	tests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{value: "today", want: time.Date(2021, 6, 15, 0, 0, 0, 0, location)},
+		{value: "Yesterday", want: time.Date(2021, 6, 14, 0, 0, 0, 0, location)},
+		{value: "24h", want: time.Date(2021, 6, 14, 14, 30, 0, 0, location)},
+		{value: "90m", want: time.Date(2021, 6, 15, 13, 0, 0, 0, location)},
+		{value: "2021-06-01", want: time.Date(2021, 6, 1, 0, 0, 0, 0, location)},
+		{value: "2021-06-01T08:00:00Z", want: time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)},
+		{value: "last week", err: true},
+		{value: "01/06/2021", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if tt.err {
+				if err == nil {
+					t.Errorf("parseSince(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSince(%q) error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
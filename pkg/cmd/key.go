@@ -0,0 +1,124 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// keyCmd represents the key command
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage the keys used to sign and verify exports",
+	Long: `Manage the ed25519 keys used to sign and verify exports. Keys are stored in the vra-cli folder of
+your user config directory (e.g. ~/.config/vra-cli/keys).
+
+# Create a signing key, and sign exports with it
+vra-cli key generate --name my-team
+vra-cli get pipeline --project "Field Demo" --out export --exportPath ./exports --signingKey my-team
+
+# Share the public key, and trust it on another machine
+vra-cli key export --name my-team > my-team.pub
+vra-cli key import --importPath my-team.pub`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  func(cmd *cobra.Command, args []string) {},
+}
+
+// generateKeyCmd represents the key generate command
+var generateKeyCmd = &cobra.Command{
+	Use:         "generate",
+	Short:       "Create a signing key",
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := integrity.GenerateKey(name)
+		if err != nil {
+			log.Fatalln("Unable to create the key:", err)
+		}
+		log.Infoln("Created key", name+", share", path, "with anyone who needs to verify your exports")
+	},
+}
+
+// listKeyCmd represents the key list command
+var listKeyCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the trusted public keys",
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		keys, err := integrity.PublicKeys()
+		if err != nil {
+			log.Fatalln("Unable to list keys:", err)
+		}
+		if APIClient.Output == "json" {
+			type key struct {
+				Name string `json:"name"`
+				ID   string `json:"id"`
+			}
+			var list []key
+			for _, k := range keys {
+				list = append(list, key{k.Name, k.ID})
+			}
+			helpers.PrettyPrint(list)
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "ID"})
+		for _, k := range keys {
+			table.Append([]string{k.Name, k.ID})
+		}
+		table.Render()
+	},
+}
+
+// importKeyCmd represents the key import command
+var importKeyCmd = &cobra.Command{
+	Use:         "import",
+	Short:       "Trust a public key for verifying signatures",
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := integrity.ImportPublicKey(importPath, name)
+		if err != nil {
+			log.Fatalln("Unable to import the key:", err)
+		}
+		log.Infoln("Trusted key", key.Name, "("+key.ID+")")
+	},
+}
+
+// exportKeyCmd represents the key export command
+var exportKeyCmd = &cobra.Command{
+	Use:         "export",
+	Short:       "Print a public key",
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := integrity.PublicKey(name)
+		if err != nil {
+			log.Fatalln("Unable to export the key:", err)
+		}
+		fmt.Print(string(key))
+	},
+}
+
+func init() {
+	keyCmd.AddCommand(generateKeyCmd)
+	generateKeyCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the key")
+	generateKeyCmd.MarkFlagRequired("name")
+
+	keyCmd.AddCommand(listKeyCmd)
+
+	keyCmd.AddCommand(importKeyCmd)
+	importKeyCmd.Flags().StringVar(&importPath, "importPath", "", "Public key file to trust")
+	importKeyCmd.Flags().StringVarP(&name, "name", "n", "", "Name to give the key (defaults to the name in the file)")
+	importKeyCmd.MarkFlagRequired("importPath")
+
+	keyCmd.AddCommand(exportKeyCmd)
+	exportKeyCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the key")
+	exportKeyCmd.MarkFlagRequired("name")
+}
@@ -0,0 +1,176 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/lint"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintVariables string
+	lintEndpoints string
+	lintLive      bool
+	lintFormat    string
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check files for problems before they are imported",
+	Long:  `Check files for problems before they are imported`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+// lintPipelineCmd represents the lint pipeline command
+var lintPipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Check Pipeline YAML files for problems",
+	Long: `Check Code Stream Pipeline YAML files for references to Variables, inputs, stages, tasks and
+Endpoints that do not exist, empty stages, duplicate tasks, unsupported task types and values that do
+not match the Pipeline schema (see vra-cli schema pipeline).
+
+Variables and Endpoints are checked against local exports with --variables and --endpoints, or against
+the Pipeline's Project on the current target with --live; otherwise they are not checked. Findings are
+printed as file:line:column: level: message [rule], or as SARIF with --format sarif, and the command
+fails if any errors are found.
+
+# Lint a folder of Pipelines against exported Variables and Endpoints
+vra-cli lint pipeline --importPath ./pipelines --variables ./variables.yaml --endpoints ./endpoints
+
+# Lint against the current target, for code scanning
+vra-cli lint pipeline --importPath ./pipelines --live --format sarif > lint.sarif`,
+	Annotations: map[string]string{"offline": "true"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if lintFormat != "text" && lintFormat != "sarif" {
+			return fmt.Errorf("--format is not valid, must be text or sarif")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		options, err := lintOptions()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		var findings []lint.Finding
+		files := helpers.GetFilePaths(importPath, ".yaml")
+		if len(files) == 0 {
+			log.Warnln("No YAML files were found in", importPath)
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			fileFindings, err := lint.Pipeline(file, content, options)
+			if err != nil {
+				log.Fatalln("Unable to lint", err)
+			}
+			findings = append(findings, fileFindings...)
+		}
+
+		failed := 0
+		for _, finding := range findings {
+			if finding.Level == lint.Error {
+				failed++
+			}
+		}
+		if lintFormat == "sarif" {
+			out, err := lint.SARIF(findings, version)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			for _, finding := range findings {
+				fmt.Println(finding)
+			}
+		}
+		if failed > 0 {
+			log.Fatalln(failed, "errors and", len(findings)-failed, "warnings found in", len(files), "files")
+		}
+		log.Infoln(len(findings), "warnings found in", len(files), "files")
+	},
+}
+
+// lintOptions returns the Variables and Endpoints that Pipelines can reference, from local exports or the current target
+func lintOptions() (lint.Options, error) {
+	var options lint.Options
+	if lintVariables != "" {
+		options.Variables = make(map[string]bool)
+		for _, variable := range codestream.ImportVariables(lintVariables) {
+			options.Variables[lint.Key(variable.Project, variable.Name)] = true
+		}
+	}
+	if lintEndpoints != "" {
+		options.Endpoints = make(map[string]bool)
+		documents, err := manifest.LoadTree(lintEndpoints)
+		if err != nil {
+			return options, err
+		}
+		for _, document := range documents {
+			if document.Kind == manifest.KindEndpoint {
+				options.Endpoints[lint.Key(document.Project, document.Name)] = true
+			}
+		}
+	}
+	if !lintLive {
+		return options, nil
+	}
+
+	// Look up the Variables and Endpoints in the Projects of the Pipelines
+	authenticate()
+	documents, err := manifest.LoadTree(importPath)
+	if err != nil {
+		return options, err
+	}
+	projects := make(map[string]bool)
+	for _, document := range documents {
+		if document.Kind == manifest.KindPipeline {
+			projects[document.Project] = true
+		}
+	}
+	if options.Variables == nil {
+		options.Variables = make(map[string]bool)
+	}
+	if options.Endpoints == nil {
+		options.Endpoints = make(map[string]bool)
+	}
+	for project := range projects {
+		variables, err := codestream.GetVariable(APIClient, "", "", project, "")
+		if err != nil {
+			return options, err
+		}
+		for _, variable := range variables {
+			options.Variables[lint.Key(project, variable.Name)] = true
+		}
+		endpoints, err := codestream.GetEndpoint(APIClient, "", "", project, "", "")
+		if err != nil {
+			return options, err
+		}
+		for _, endpoint := range endpoints {
+			options.Endpoints[lint.Key(project, endpoint.Name)] = true
+		}
+	}
+	return options, nil
+}
+
+func init() {
+	lintCmd.AddCommand(lintPipelineCmd)
+	lintPipelineCmd.Flags().StringVar(&importPath, "importPath", "", "Pipeline YAML file, or folder of files, to lint")
+	lintPipelineCmd.Flags().StringVar(&lintVariables, "variables", "", "Variables YAML file, or folder of files, exported by get variable --exportPath")
+	lintPipelineCmd.Flags().StringVar(&lintEndpoints, "endpoints", "", "Endpoint YAML file, or folder of files, exported by get endpoint --out export")
+	lintPipelineCmd.Flags().BoolVar(&lintLive, "live", false, "Check Variables and Endpoints against the Pipelines' Projects on the current target")
+	lintPipelineCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format (text|sarif)")
+	lintPipelineCmd.MarkFlagRequired("importPath")
+}
@@ -0,0 +1,150 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/backup"
+	"github.com/sammcgeown/vra-cli/pkg/util/config"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateFrom     string
+	migrateTo       string
+	migrateProjects []string
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy Projects and their content from one target to another",
+	Long: `Copy Code Stream Pipelines, Endpoints and Variables, Cloud Assembly Cloud Templates and Property Groups,
+and optionally Custom Integrations and vRO Workflows, Actions and Packages, from one target to another.
+
+Each --project is a source Project, optionally followed by the name of the Project to copy it to. The
+Project field of Pipelines and Endpoints, and the Project of Cloud Templates and Property Groups, are
+rewritten to the destination Project. Objects that already exist on the destination are updated.
+
+# Promote a Project from dev to prod
+vra-cli migrate --from dev --to prod --project "Field Demo"
+
+# Copy two Projects to the current target under new names, with a vRO Package
+vra-cli migrate --from dev --project "Field Demo:Field Demo Prod" --project "Build:Build Prod" --package com.example.demo`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		mappings := make([][2]string, 0, len(migrateProjects))
+		for _, p := range migrateProjects {
+			parts := strings.SplitN(p, ":", 2)
+			if parts[0] == "" {
+				log.Fatalln("Invalid --project", strconv.Quote(p), "- use Source or Source:Destination")
+			}
+			if len(parts) == 1 || parts[1] == "" {
+				parts = []string{parts[0], parts[0]}
+			}
+			mappings = append(mappings, [2]string{parts[0], parts[1]})
+		}
+		if len(mappings) == 0 && !backupCustomIntegrations && len(backupWorkflows)+len(backupActions)+len(backupPackages) == 0 {
+			log.Fatalln("Nothing to migrate - use --project, --customIntegrations, --workflow, --action or --package")
+		}
+
+		source, err := connectTarget(migrateFrom)
+		if err != nil {
+			log.Fatalln("Unable to connect to", migrateFrom+":", err)
+		}
+		if migrateTo != "" && migrateTo != targetConfig.Name {
+			destination, err := connectTarget(migrateTo)
+			if err != nil {
+				log.Fatalln("Unable to connect to", migrateTo+":", err)
+			}
+			previous := APIClient
+			APIClient = destination
+			defer func() { APIClient = previous }()
+		}
+		if source.Config.Name == APIClient.Config.Name {
+			for _, mapping := range mappings {
+				if mapping[0] == mapping[1] {
+					log.Fatalln("The source and destination of", mapping[0], "are the same - use --to or --project Source:Destination")
+				}
+			}
+		}
+
+		tempDir, err := ioutil.TempDir("", "vra-cli-migrate")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		var documents []*manifest.Document
+		var skipped []backup.Object
+		for i, mapping := range mappings {
+			log.Infoln("Reading", mapping[0], "from", source.Config.Name)
+			dir := filepath.Join(tempDir, strconv.Itoa(i))
+			m := &backup.Manifest{FormatVersion: backup.FormatVersion, Version: version, Target: source.Config.Name, Server: source.Config.Server, Project: mapping[0], Created: time.Now().UTC()}
+			if err := backupProject(source, dir, m, mapping[0]); err != nil {
+				log.Fatalln("Unable to read", mapping[0], "from", source.Config.Name+":", err)
+			}
+			project := ""
+			if mapping[1] != mapping[0] {
+				project = mapping[1]
+			}
+			projectDocuments, err := loadBackup(dir, m, project)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			documents = append(documents, projectDocuments...)
+			skipped = append(skipped, m.Skipped...)
+		}
+
+		dir := filepath.Join(tempDir, "shared")
+		m := &backup.Manifest{FormatVersion: backup.FormatVersion, Version: version, Target: source.Config.Name, Server: source.Config.Server, Created: time.Now().UTC()}
+		if err := backupShared(source, dir, m); err != nil {
+			log.Fatalln("Unable to read from", source.Config.Name+":", err)
+		}
+		sharedDocuments, err := loadBackup(dir, m, "")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		documents = append(documents, sharedDocuments...)
+
+		log.Infoln("Copying", len(documents), "objects to", APIClient.Config.Name)
+		applyDocuments(documents, skipped)
+	},
+}
+
+// connectTarget returns a client for a configured target, sharing the options of the current client
+func connectTarget(name string) (*types.APIClientOptions, error) {
+	cfg, err := config.GetTargetConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	client := *APIClient
+	client.Config = cfg
+	if err := auth.ValidateConfiguration(&client); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func init() {
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", "", "Target to copy from")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Target to copy to (defaults to the current target)")
+	migrateCmd.Flags().StringSliceVarP(&migrateProjects, "project", "p", []string{}, "Project to copy, as Source or Source:Destination (can be repeated)")
+	migrateCmd.Flags().BoolVar(&backupCustomIntegrations, "customIntegrations", false, "Include all Custom Integrations")
+	migrateCmd.Flags().StringSliceVar(&backupWorkflows, "workflow", []string{}, "vRO Workflow to copy (can be repeated)")
+	migrateCmd.Flags().StringSliceVar(&backupActions, "action", []string{}, "vRO Action to copy, by name or module/name (can be repeated)")
+	migrateCmd.Flags().StringSliceVar(&backupPackages, "package", []string{}, "vRO Package to copy (can be repeated)")
+	migrateCmd.MarkFlagRequired("from")
+}
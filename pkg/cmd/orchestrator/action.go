@@ -7,13 +7,18 @@ package orchestrator
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 )
@@ -30,6 +35,9 @@ func GetAction(APIClient *types.APIClientOptions, id string, category string, na
 		if err != nil {
 			return nil, err
 		}
+		if queryResponse.IsError() {
+			return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
+		}
 		Actions = append(Actions, queryResponse.Result().(*types.WsAction))
 		return Actions, nil
 	}
@@ -55,13 +63,36 @@ func GetAction(APIClient *types.APIClientOptions, id string, category string, na
 	}
 	APIClient.RESTClient.QueryParam.Del("conditions")
 
-	for _, value := range queryResponse.Result().(*types.InventoryItemsList).Link {
-		for _, attribute := range value.Attributes {
-			if attribute.Name == "id" {
-				Action, _ := GetAction(APIClient, attribute.Value, "", "")
-				Actions = append(Actions, Action...)
+	// Look up each action in the list, in parallel, keeping the list order
+	links := queryResponse.Result().(*types.InventoryItemsList).Link
+	results := make([]*types.WsAction, len(links))
+	err = helpers.ForEachParallel(len(links), APIClient.Parallel, func(i int) error {
+		attributes := attributeMap(links[i].Attributes)
+		if attributes["id"] == "" {
+			return nil
+		}
+		if APIClient.Summary && hasAttributes(attributes, "name", "module", "version") {
+			results[i] = &types.WsAction{
+				Href:        links[i].Href,
+				ID:          attributes["id"],
+				Name:        attributes["name"],
+				Module:      attributes["module"],
+				Version:     attributes["version"],
+				Description: attributes["description"],
+				Fqn:         attributes["fqn"],
 			}
-
+			return nil
+		}
+		Action, err := GetAction(APIClient, attributes["id"], "", "")
+		if err != nil {
+			return fmt.Errorf("action %s: %w", attributes["id"], err)
+		}
+		results[i] = Action[0]
+		return nil
+	})
+	for _, Action := range results {
+		if Action != nil {
+			Actions = append(Actions, Action)
 		}
 	}
 	return Actions, err
@@ -90,12 +121,15 @@ func ExportAction(APIClient *types.APIClientOptions, id string, name string, pat
 	if queryResponse.IsError() {
 		return errors.New(queryResponse.Status())
 	}
-	return nil
+	return integrity.Record(filepath.Join(exportPath, name+".action"), APIClient.SigningKey)
 }
 
 // ImportAction - imports a action
 func ImportAction(APIClient *types.APIClientOptions, path string, categoryName string) error {
 	log.Debugln("Path:", path, "categoryName:", categoryName, "Overwrite:", APIClient.Force)
+	if err := integrity.Check(APIClient.Integrity, path); err != nil {
+		return err
+	}
 	zipFileBytes, _ := ioutil.ReadFile(path)
 	APIClient.RESTClient.QueryParam.Set("categoryName", categoryName)
 	APIClient.RESTClient.QueryParam.Set("overwrite", strconv.FormatBool(APIClient.Force))
@@ -108,6 +142,9 @@ func ImportAction(APIClient *types.APIClientOptions, path string, categoryName s
 	if err != nil {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
+	if queryResponse.StatusCode() == http.StatusConflict { // The object exists, and overwrite is false
+		return fmt.Errorf("%w: %s", conflict.ErrExists, queryResponse.Error().(*types.Exception).Message)
+	}
 	if queryResponse.IsError() {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
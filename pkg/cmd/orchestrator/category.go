@@ -6,7 +6,11 @@ package orchestrator
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/cache"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,6 +27,9 @@ func GetCategoryByID(APIClient *types.APIClientOptions, id string) (*types.WsCat
 	if err != nil {
 		return nil, err
 	}
+	if queryResponse.IsError() {
+		return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
+	}
 
 	log.Debugln(string(queryResponse.Body()))
 
@@ -33,7 +40,6 @@ func GetCategoryByID(APIClient *types.APIClientOptions, id string) (*types.WsCat
 
 // GetCategoryByName returns the category by name
 func GetCategoryByName(APIClient *types.APIClientOptions, categoryName string, categoryType string) ([]*types.WsCategory, error) {
-	var Categories []*types.WsCategory
 	APIClient.RESTClient.QueryParam.Set("conditions", "name~"+categoryName)
 
 	queryResponse, err := APIClient.RESTClient.R().
@@ -46,23 +52,45 @@ func GetCategoryByName(APIClient *types.APIClientOptions, categoryName string, c
 	}
 	APIClient.RESTClient.QueryParam.Del("conditions")
 
-	for _, value := range queryResponse.Result().(*types.InventoryItemsList).Link {
-		for _, attribute := range value.Attributes {
-			if attribute.Name == "id" {
-				Category, _ := GetCategoryByID(APIClient, attribute.Value)
-				Categories = append(Categories, Category)
-			}
+	return getCategoriesFromLinks(APIClient, queryResponse.Result().(*types.InventoryItemsList).Link)
+}
 
+// GetCategoryID resolves a category name, or path (e.g. path/to/category), to its ID, using the local cache
+func GetCategoryID(APIClient *types.APIClientOptions, category string, categoryType string) (string, error) {
+	var CategoryID string
+	key := "category:" + categoryType + ":" + category
+	if cache.Get(APIClient, key, &CategoryID) {
+		return CategoryID, nil
+	}
+	categoryName := (strings.Split(category, "/"))[len(strings.Split(category, "/"))-1]
+	categories, err := GetCategoryByName(APIClient, categoryName, categoryType)
+	if err != nil {
+		return "", err
+	}
+	if len(categories) == 0 {
+		return "", errors.New("Unable to find category: " + categoryName)
+	} else if len(categories) == 1 {
+		// Only one category found
+		log.Debugln("Category found:", categories[0].Name, categories[0].ID)
+		CategoryID = categories[0].ID
+	} else {
+		for _, matchedCategory := range categories {
+			if matchedCategory.Path == category {
+				log.Debugln("Category ID:", matchedCategory.ID)
+				CategoryID = matchedCategory.ID
+				break
+			}
+		}
+		if CategoryID == "" {
+			return "", errors.New("Multiple categories found, try using a more specific category - e.g.: path/to/category")
 		}
 	}
-
-	return Categories, nil
+	cache.Set(APIClient, key, CategoryID)
+	return CategoryID, nil
 }
 
 // GetCategory returns the categories
 func GetCategory(APIClient *types.APIClientOptions, root bool, categoryType string) ([]*types.WsCategory, error) {
-	var Categories []*types.WsCategory
-
 	if categoryType != "" {
 		APIClient.RESTClient.QueryParam.Set("categoryType", categoryType)
 	}
@@ -79,16 +107,41 @@ func GetCategory(APIClient *types.APIClientOptions, root bool, categoryType stri
 		return nil, err
 	}
 
-	for _, value := range queryResponse.Result().(*types.InventoryItemsList).Link {
-		for _, attribute := range value.Attributes {
-			if attribute.Name == "id" {
-				Category, _ := GetCategoryByID(APIClient, attribute.Value)
-				Categories = append(Categories, Category)
-			}
+	return getCategoriesFromLinks(APIClient, queryResponse.Result().(*types.InventoryItemsList).Link)
+}
 
+// getCategoriesFromLinks resolves a list of category links, in parallel, keeping the list order
+func getCategoriesFromLinks(APIClient *types.APIClientOptions, links []types.Link) ([]*types.WsCategory, error) {
+	var Categories []*types.WsCategory
+	results := make([]*types.WsCategory, len(links))
+	err := helpers.ForEachParallel(len(links), APIClient.Parallel, func(i int) error {
+		attributes := attributeMap(links[i].Attributes)
+		if attributes["id"] == "" {
+			return nil
+		}
+		if APIClient.Summary && hasAttributes(attributes, "name", "type", "path") {
+			results[i] = &types.WsCategory{
+				Href: links[i].Href,
+				ID:   attributes["id"],
+				Name: attributes["name"],
+				Type: attributes["type"],
+				Path: attributes["path"],
+			}
+			return nil
+		}
+		Category, err := GetCategoryByID(APIClient, attributes["id"])
+		if err != nil {
+			return fmt.Errorf("category %s: %w", attributes["id"], err)
+		}
+		results[i] = Category
+		return nil
+	})
+	for _, Category := range results {
+		if Category != nil {
+			Categories = append(Categories, Category)
 		}
 	}
-	return Categories, nil
+	return Categories, err
 }
 
 // CreateCategory creates a category
@@ -153,6 +206,7 @@ func UpdateCategory(APIClient *types.APIClientOptions, categoryID string, catego
 	if queryResponse.IsError() {
 		return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
+	cache.Invalidate(APIClient, "category:")
 
 	updatedCategory, err := GetCategoryByID(APIClient, categoryID)
 	if err != nil {
@@ -176,5 +230,6 @@ func DeleteCategory(APIClient *types.APIClientOptions, categoryID string) error
 	if queryResponse.IsError() {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
+	cache.Invalidate(APIClient, "category:")
 	return nil
 }
@@ -4,7 +4,28 @@ SPDX-License-Identifier: BSD-2-Clause
 */
 package orchestrator
 
+import "github.com/sammcgeown/vra-cli/pkg/util/types"
+
 var (
 	//apiVersion          = "2019-10-17"
 	expandProjects bool = true
 )
+
+// attributeMap flattens the name/value attributes of an inventory link
+func attributeMap(attributes []types.Attributes) map[string]string {
+	values := make(map[string]string, len(attributes))
+	for _, attribute := range attributes {
+		values[attribute.Name] = attribute.Value
+	}
+	return values
+}
+
+// hasAttributes returns true if all of the named attributes are present
+func hasAttributes(attributes map[string]string, names ...string) bool {
+	for _, name := range names {
+		if _, ok := attributes[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -7,10 +7,13 @@ package orchestrator
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strconv"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 )
 
@@ -72,6 +75,9 @@ func GetPackage(APIClient *types.APIClientOptions, name string) ([]*types.WsPack
 			SetError(&types.Exception{}).
 			Get("/vco/api/packages/" + name)
 		if err != nil {
+			return nil, err
+		}
+		if queryResponse.IsError() {
 			return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
 		}
 		Categories = append(Categories, queryResponse.Result().(*types.WsPackage))
@@ -84,19 +90,33 @@ func GetPackage(APIClient *types.APIClientOptions, name string) ([]*types.WsPack
 		Get("/vco/api/packages")
 
 	if err != nil {
+		return nil, err
+	}
+	if queryResponse.IsError() {
 		return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
 
-	for _, value := range queryResponse.Result().(*types.WsPackages).Link {
-		for _, attribute := range value.Attribute {
+	// Look up each package in the list, in parallel, keeping the list order
+	links := queryResponse.Result().(*types.WsPackages).Link
+	results := make([]*types.WsPackage, len(links))
+	err = helpers.ForEachParallel(len(links), APIClient.Parallel, func(i int) error {
+		for _, attribute := range links[i].Attribute {
 			if attribute.Name == "name" {
-				Category, _ := GetPackage(APIClient, attribute.Value)
-				Categories = append(Categories, Category...)
+				Category, err := GetPackage(APIClient, attribute.Value)
+				if err != nil {
+					return fmt.Errorf("package %s: %w", attribute.Value, err)
+				}
+				results[i] = Category[0]
 			}
-
+		}
+		return nil
+	})
+	for _, Category := range results {
+		if Category != nil {
+			Categories = append(Categories, Category)
 		}
 	}
-	return Categories, nil
+	return Categories, err
 }
 
 // ExportPackage exports a package
@@ -126,11 +146,14 @@ func ExportPackage(APIClient *types.APIClientOptions, name string, options types
 	if err != nil {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
-	return nil
+	return integrity.Record(filepath.Join(exportPath, name+".package"), APIClient.SigningKey)
 }
 
 // CreatePackage imports a Package
 func CreatePackage(APIClient *types.APIClientOptions, importPath string, importOptions types.ImportPackageOptions) error {
+	if err := integrity.Check(APIClient.Integrity, importPath); err != nil {
+		return err
+	}
 
 	APIClient.RESTClient.QueryParam.Set("importValues", strconv.FormatBool(importOptions.ImportConfigurationAttributeValues))
 	APIClient.RESTClient.QueryParam.Set("importSecureValues", strconv.FormatBool(importOptions.ImportConfigSecureStringAttributeValues))
@@ -156,6 +179,9 @@ func CreatePackage(APIClient *types.APIClientOptions, importPath string, importO
 
 // GetPackageDetails imports a Package and returns the import details
 func GetPackageDetails(APIClient *types.APIClientOptions, importPath string, importOptions types.ImportPackageOptions) (*types.ImportPackageDetails, error) {
+	if err := integrity.Check(APIClient.Integrity, importPath); err != nil {
+		return nil, err
+	}
 	packageBytes, err := ioutil.ReadFile(importPath)
 	if err != nil {
 		return nil, err
@@ -7,13 +7,18 @@ package orchestrator
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 )
@@ -30,6 +35,9 @@ func GetWorkflow(APIClient *types.APIClientOptions, id string, category string,
 		if err != nil {
 			return nil, err
 		}
+		if queryResponse.IsError() {
+			return nil, errors.New(queryResponse.Error().(*types.Exception).Message)
+		}
 		Workflows = append(Workflows, queryResponse.Result().(*types.WsWorkflow))
 		return Workflows, nil
 	}
@@ -56,14 +64,35 @@ func GetWorkflow(APIClient *types.APIClientOptions, id string, category string,
 		return nil, err
 	}
 
-	for _, value := range queryResponse.Result().(*types.InventoryItemsList).Link {
-		for _, attribute := range value.Attributes {
-			if attribute.Name == "id" {
-
-				Workflow, _ := GetWorkflow(APIClient, attribute.Value, "", "")
-				Workflows = append(Workflows, Workflow...)
+	// Look up each workflow in the list, in parallel, keeping the list order
+	links := queryResponse.Result().(*types.InventoryItemsList).Link
+	results := make([]*types.WsWorkflow, len(links))
+	err = helpers.ForEachParallel(len(links), APIClient.Parallel, func(i int) error {
+		attributes := attributeMap(links[i].Attributes)
+		if attributes["id"] == "" {
+			return nil
+		}
+		if APIClient.Summary && hasAttributes(attributes, "name", "version", "categoryId") {
+			results[i] = &types.WsWorkflow{
+				Href:        links[i].Href,
+				ID:          attributes["id"],
+				Name:        attributes["name"],
+				Version:     attributes["version"],
+				Description: attributes["description"],
+				CategoryID:  attributes["categoryId"],
 			}
-
+			return nil
+		}
+		Workflow, err := GetWorkflow(APIClient, attributes["id"], "", "")
+		if err != nil {
+			return fmt.Errorf("workflow %s: %w", attributes["id"], err)
+		}
+		results[i] = Workflow[0]
+		return nil
+	})
+	for _, Workflow := range results {
+		if Workflow != nil {
+			Workflows = append(Workflows, Workflow)
 		}
 	}
 	return Workflows, err
@@ -92,12 +121,15 @@ func ExportWorkflow(APIClient *types.APIClientOptions, id string, name string, p
 	if queryResponse.IsError() {
 		return errors.New(queryResponse.Status())
 	}
-	return nil
+	return integrity.Record(filepath.Join(exportPath, name+".zip"), APIClient.SigningKey)
 }
 
 // ImportWorkflow - imports a workflow
 func ImportWorkflow(APIClient *types.APIClientOptions, path string, categoryID string) error {
 	log.Debugln("Path:", path, "CategoryID:", categoryID, "Overwrite:", APIClient.Force)
+	if err := integrity.Check(APIClient.Integrity, path); err != nil {
+		return err
+	}
 	zipFileBytes, _ := ioutil.ReadFile(path)
 	APIClient.RESTClient.QueryParam.Set("categoryId", categoryID)
 	APIClient.RESTClient.QueryParam.Set("overwrite", strconv.FormatBool(APIClient.Force))
@@ -110,6 +142,9 @@ func ImportWorkflow(APIClient *types.APIClientOptions, path string, categoryID s
 	if err != nil {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
+	if queryResponse.StatusCode() == http.StatusConflict { // The object exists, and overwrite is false
+		return fmt.Errorf("%w: %s", conflict.ErrExists, queryResponse.Error().(*types.Exception).Message)
+	}
 	if queryResponse.IsError() {
 		return errors.New(queryResponse.Error().(*types.Exception).Message)
 	}
@@ -10,8 +10,8 @@ import (
 	"strconv"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
-
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
@@ -67,16 +67,14 @@ vra-cli get package --name <name> --out export --exportPath <path> --exportConfi
 				}
 				table.Render()
 			} else if APIClient.Output == "export" {
-				// Export the Package
-				for _, Package := range response {
-
-					err := orchestrator.ExportPackage(APIClient, Package.Name, exportOptions, exportPath)
-					if err != nil {
-						log.Warnln("Unable to export Package: ", err)
-					} else {
-						log.Infoln("Package", Package.Name, "exported")
-					}
+				// Export the Packages
+				names := make([]string, len(response))
+				for i, Package := range response {
+					names[i] = Package.Name
 				}
+				exportObjects(names, "Packages", func(client *types.APIClientOptions, i int) error {
+					return orchestrator.ExportPackage(client, response[i].Name, exportOptions, exportPath)
+				})
 
 			} else {
 				helpers.PrettyPrint(response)
@@ -121,12 +119,18 @@ vra-cli delete package --name <name> --deleteOption deletePackageKeepingShared`,
 var createPackageCmd = &cobra.Command{
 	Use:   "package",
 	Short: "Create a Package",
-	Long:  `Create a Package`,
+	Long: `Create a Package from a .package file, or a folder of them.
+
+A Package that already exists fails the import, unless --on-conflict is skip or overwrite, or --force is set.
+
+# Import a Package, overwriting it if it exists
+vra-cli create package --importPath ./packages/com.example.package --on-conflict overwrite`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 
+		var results []applyResult
 		for _, path := range helpers.GetFilePaths(importPath, ".package") {
 			log.Debugln("Importing Package:", path)
 			packageDetails, packageErr := orchestrator.GetPackageDetails(APIClient, path, importOptions)
@@ -135,9 +139,6 @@ var createPackageCmd = &cobra.Command{
 			}
 
 			log.Infoln("Importing", packageDetails.PackageName)
-			if packageDetails.PackageAlreadyExists && !APIClient.Force {
-				log.Warnln("Package already exists, only new or newer content will be imported, use --force to override.")
-			}
 			if !packageDetails.CertificateValid {
 				helpers.PrettyPrint(packageDetails.CertificateInfo)
 				if !helpers.AskForConfirmation("Certificate is not valid") {
@@ -152,29 +153,17 @@ var createPackageCmd = &cobra.Command{
 				}
 			}
 
-			if packageDetails.PackageAlreadyExists {
-				table := tablewriter.NewWriter(os.Stdout)
-				table.SetHeader([]string{"Name", "Type", "Version", "Imported"})
-				for _, value := range packageDetails.ImportElementDetails {
-					table.Append([]string{value.FileObjectName, value.Type, value.FileObjectVersion, strconv.FormatBool(value.ImportIt)})
-				}
-				table.Render()
-				if !helpers.AskForConfirmation("Package already exists, continue?") {
-					log.Fatalln("Package already exists, user declined")
-				}
-			}
-
-			importError := orchestrator.CreatePackage(APIClient, path, importOptions)
-			if importError != nil {
-				log.Errorln("Unable to import Package: ", importError)
-			} else {
-				Package, err := orchestrator.GetPackage(APIClient, packageDetails.PackageName)
-				if err != nil {
-					log.Errorln("Package imported OK, but I'm unable to get imported Package details: ", err)
+			// An existing Package fails the import, unless it is skipped or overwritten (--force) by the conflict policy
+			result := importOrchestratorPath(APIClient, manifest.KindPackage, path, orchestratorPolicy(), func() error {
+				if err := checkPackageConflict(APIClient, path, importOptions); err != nil {
+					return err
 				}
-				log.Infoln("Package imported:", Package[0].Name, "with ID:", Package[0].ID)
-			}
+				return orchestrator.CreatePackage(APIClient, path, importOptions)
+			})
+			result.Name = packageDetails.PackageName
+			results = append(results, result)
 		}
+		reportImport(results)
 
 	},
 }
@@ -204,4 +193,7 @@ func init() {
 	createPackageCmd.MarkFlagRequired("importPath")
 	// Update (alias of create for package import)
 	updateCmd.AddCommand(createPackageCmd)
+	// Completions
+	registerCompletions(getPackageCmd, "package")
+	registerCompletions(delPackageCmd, "package")
 }
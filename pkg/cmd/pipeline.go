@@ -9,13 +9,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
-	"github.com/mitchellh/mapstructure"
 	"github.com/olekukonko/tablewriter"
 	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/batch"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
+	"github.com/sammcgeown/vra-cli/pkg/util/graph"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
@@ -25,12 +27,26 @@ import (
 var state string
 var printForm bool
 var dependencies bool
+var graphFormat string
 
 // getPipelineCmd represents the pipeline command
 var getPipelineCmd = &cobra.Command{
 	Use:   "pipeline",
 	Short: "Get Pipelines",
-	Long:  `Get Code Stream Pipelines by ID, name or status`,
+	Long: `Get Code Stream Pipelines by ID, name or status
+
+# Export a Pipeline and everything it depends on, following nested Pipelines
+vra-cli get pipeline --name "Deploy" --project "Field Demo" --exportDependencies --exportPath ./deploy
+
+# Render the dependencies of a Pipeline as a Graphviz DOT or Mermaid graph
+vra-cli get pipeline --name "Deploy" --project "Field Demo" --graph dot | dot -Tsvg > deploy.svg`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		switch graphFormat {
+		case "", "dot", "mermaid":
+			return nil
+		}
+		return errors.New("--graph is not valid, must be dot or mermaid")
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 
 		response, err := codestream.GetPipeline(APIClient, id, name, projectName, exportPath)
@@ -43,105 +59,118 @@ var getPipelineCmd = &cobra.Command{
 			log.Warnln("No results found")
 		}
 
+		if dependencies || graphFormat != "" {
+			g, err := codestream.GetDependencyGraph(APIClient, response)
+			if err != nil {
+				log.Fatalln("Unable to resolve Pipeline dependencies:", err)
+			}
+			if dependencies {
+				results := exportDependencies(g, exportPath)
+				batch.Print(os.Stdout, results, APIClient.Output)
+				if failed := batch.Failures(results); failed > 0 {
+					log.Errorln(failed, "of", len(results), "objects could not be exported")
+				}
+			}
+			switch graphFormat {
+			case "dot":
+				fmt.Print(g.DOT())
+			case "mermaid":
+				fmt.Print(g.Mermaid())
+			}
+			return
+		}
+
 		if APIClient.Output == "json" {
 			helpers.PrettyPrint(response)
 		} else if APIClient.Output == "export" {
-			for _, c := range response {
-				err := codestream.ExportYaml(APIClient, c.ID, c.Name, c.Project, exportPath, "pipelines")
-				if err != nil {
-					log.Infoln("Pipeline", c.Name, "export failed: ", err)
-				} else {
-					log.Infoln("Pipeline", c.Name, "exported successfully")
-				}
+			names := make([]string, len(response))
+			for i, c := range response {
+				names[i] = c.Name
 			}
+			exportObjects(names, "Pipelines", func(client *types.APIClientOptions, i int) error {
+				return codestream.ExportYaml(client, response[i].ID, response[i].Name, response[i].Project, exportPath, "pipelines")
+			})
 		} else if printForm {
 			// Get the input form
 			for _, c := range response {
 				helpers.PrettyPrint(c.Input)
 			}
 		} else {
-			var endpoints []string
-			var variables []string
-			var pipelines []string
-			var customintegrations []string
-
 			// Print result table
 			table := tablewriter.NewWriter(os.Stdout)
 			table.SetHeader([]string{"Id", "Name", "Project", "Description"})
 			for _, c := range response {
-				if c.Workspace.Endpoint != "" {
-					endpoints = append(endpoints, c.Workspace.Endpoint)
-				}
-
 				table.Append([]string{c.ID, c.Name, c.Project, c.Description})
-				for _, s := range c.Stages {
-					stage := types.PipelineStage{}
-					mapstructure.Decode(s, &stage)
-					// Loop through the Stage Tasks
-					for n, t := range stage.Tasks {
-						taskString := fmt.Sprintf("%v", t)
-						rxp := regexp.MustCompile(`\$\{var\.(.*?)\}`) // Match ${var.name}
-						variableMatches := rxp.FindAllStringSubmatch(taskString, -1)
-						for _, v := range variableMatches {
-							variables = append(variables, v[1])
-						}
-						task := types.PipelineTask{}
-						mapstructure.Decode(t, &task)
-						if len(task.Endpoints) > 0 {
-							for _, e := range task.Endpoints {
-								endpoints = append(endpoints, e)
-							}
-						}
-						//helpers.PrettyPrint(task)
-						if task.Type == "Pipeline" {
-							pipelines = append(pipelines, task.Input.Pipeline)
-						}
-						if task.Type == "Custom" {
-							customintegrations = append(customintegrations, task.Input.Name)
-						}
-						log.Debugln("-- [Task]", n, "(", task.Type, ")")
-					}
-				}
-				// if dependencies {
-				// 	variables = helpers.RemoveDuplicateStrings(variables)
-				// 	sort.Strings(variables)
-				// 	if len(variables) > 0 {
-				// 		log.Infoln(c.Name, "depends on Variables:", strings.Join(variables, ", "))
-				// 		for _, v := range variables {
-				// 			codestream.GetVariable(APIClient, "", v, c.Project, exportPath)
-				// 		}
-				// 	}
-				// 	pipelines = helpers.RemoveDuplicateStrings(pipelines)
-				// 	sort.Strings(pipelines)
-				// 	if len(pipelines) > 0 {
-				// 		log.Infoln(c.Name, "depends on Pipelines:", strings.Join(pipelines, ", "))
-				// 		for _, p := range pipelines {
-				// 			codestream.GetPipeline(APIClient, "", p, c.Project, filepath.Join(exportPath, "pipelines"))
-				// 		}
-				// 	}
-				// 	endpoints = helpers.RemoveDuplicateStrings(endpoints)
-				// 	sort.Strings(endpoints)
-				// 	if len(endpoints) > 0 {
-				// 		log.Infoln(c.Name, "depends on Endpoints:", strings.Join(endpoints, ", "))
-				// 		for _, e := range endpoints {
-				// 			codestream.GetEndpoint(APIClient, "", e, c.Project, "", filepath.Join(exportPath, "endpoints"))
-				// 		}
-				// 	}
-				// 	customintegrations = helpers.RemoveDuplicateStrings(customintegrations)
-				// 	sort.Strings(customintegrations)
-				// 	if len(customintegrations) > 0 {
-				// 		log.Infoln(c.Name, "depends on Custom Integrations:", strings.Join(customintegrations, ", "))
-				// 		for _, ci := range customintegrations {
-				// 			codestream.GetCustomIntegration(APIClient, "", ci)
-				// 		}
-				// 	}
-				// }
 			}
 			table.Render()
 		}
 	},
 }
 
+// exportDependencies exports the objects in a Pipeline dependency graph to a folder for each kind
+func exportDependencies(g *graph.Graph, path string) []batch.Result {
+	if path == "" {
+		path, _ = os.Getwd()
+	}
+	folders := map[string]string{
+		manifest.KindPipeline:          filepath.Join(path, "pipelines"),
+		manifest.KindEndpoint:          filepath.Join(path, "endpoints"),
+		manifest.KindVariable:          filepath.Join(path, "variables"),
+		manifest.KindCustomIntegration: filepath.Join(path, "customintegrations"),
+	}
+	for _, folder := range folders {
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	names := make([]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		names[i] = node.Kind + " " + node.Name
+	}
+	clients := workerClients(len(g.Nodes))
+	return batch.Run("Exporting", names, APIClient.Parallel, func(worker, i int) (string, error) {
+		APIClient := clients[worker]
+		node := g.Nodes[i]
+		folder := folders[node.Kind]
+		if node.Missing {
+			return "", errors.New("not found")
+		}
+		switch node.Kind {
+		case manifest.KindPipeline:
+			if err := codestream.ExportYaml(APIClient, "", node.Name, node.Project, folder, "pipelines"); err != nil {
+				return "", err
+			}
+		case manifest.KindEndpoint:
+			if err := codestream.ExportYaml(APIClient, "", node.Name, node.Project, folder, "endpoints"); err != nil {
+				return "", err
+			}
+		case manifest.KindVariable:
+			found, err := codestream.GetVariable(APIClient, "", node.Name, node.Project, "")
+			if err != nil {
+				return "", err
+			}
+			if len(found) == 0 {
+				return "", errors.New("not found in " + node.Project)
+			}
+			if err := codestream.ExportVariable(APIClient, found[0], folder); err != nil {
+				return "", err
+			}
+		case manifest.KindCustomIntegration:
+			found, err := codestream.GetCustomIntegration(APIClient, "", node.Name)
+			if err != nil {
+				return "", err
+			}
+			if len(found) == 0 {
+				return "", errors.New("not found")
+			}
+			if err := codestream.ExportCustomIntegration(APIClient, *found[0], folder, APIClient.Force); err != nil {
+				return "", err
+			}
+		}
+		return "exported", nil
+	})
+}
+
 // updatePipelineCmd represents the pipeline update command
 var updatePipelineCmd = &cobra.Command{
 	Use:   "pipeline",
@@ -178,14 +207,7 @@ vra-cli update pipeline --importPath "/Users/sammcgeown/Desktop/pipelines/SSH Ex
 			if len(yamlFilePaths) == 0 {
 				log.Warnln("No YAML files were found in", importPath)
 			}
-			for _, yamlFilePath := range yamlFilePaths {
-				yamlFileName := filepath.Base(yamlFilePath)
-				err := codestream.ImportYaml(APIClient, yamlFilePath, "apply", "", "endpoint")
-				if err != nil {
-					log.Warnln("Failed to import", yamlFilePath, "as Pipeline", err)
-				}
-				fmt.Println("Imported", yamlFileName, "successfully - Pipeline updated.")
-			}
+			reportImport(importFiles(yamlFilePaths, "", onConflict(conflict.Overwrite)))
 		}
 	},
 }
@@ -198,6 +220,9 @@ var createPipelineCmd = &cobra.Command{
 	
 # Create from YAML
 vra-cli create pipeline --importPath "/Users/sammcgeown/Desktop/pipelines/SSH Exports.yaml"
+
+# Create from a template, with the values for an environment (see vra-cli render)
+vra-cli create pipeline --importPath pipeline.yaml.tmpl --values prod.yaml
 	`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		return nil
@@ -207,15 +232,7 @@ vra-cli create pipeline --importPath "/Users/sammcgeown/Desktop/pipelines/SSH Ex
 		if len(yamlFilePaths) == 0 {
 			log.Warnln("No YAML files were found in", importPath)
 		}
-		for _, yamlFilePath := range yamlFilePaths {
-			yamlFileName := filepath.Base(yamlFilePath)
-			err := codestream.ImportYaml(APIClient, yamlFilePath, "create", projectName, "pipeline")
-			if err != nil {
-				log.Warnln("Failed to import", yamlFilePath, "as Pipeline", err)
-			} else {
-				fmt.Println("Imported", yamlFileName, "successfully - Pipeline created.")
-			}
-		}
+		reportImport(importFiles(yamlFilePaths, projectName, onConflict(conflict.Fail)))
 	},
 }
 
@@ -249,7 +266,7 @@ vra-cli delete pipeline --project "My Project"
 			if err != nil {
 				log.Errorln("Delete Pipelines in "+projectName+" failed:", err)
 			} else {
-				log.Infoln(len(response), "Pipelines deleted")
+				reportDeleted(response, "Pipelines")
 			}
 
 		}
@@ -265,21 +282,27 @@ func init() {
 	getPipelineCmd.Flags().StringVarP(&projectName, "project", "p", "", "List pipeline in project")
 	getPipelineCmd.Flags().StringVarP(&exportPath, "exportPath", "", "", "Path to export objects - relative or absolute location")
 	getPipelineCmd.Flags().BoolVarP(&printForm, "form", "f", false, "Return pipeline inputs form(s)")
-	getPipelineCmd.Flags().BoolVarP(&dependencies, "exportDependencies", "", false, "Export Pipeline dependencies (Endpoint, Pipelines, Variables, Custom Integrations)")
+	getPipelineCmd.Flags().BoolVarP(&dependencies, "exportDependencies", "", false, "Export Pipelines and their dependencies (Endpoints, nested Pipelines, Variables, Custom Integrations) to --exportPath")
+	getPipelineCmd.Flags().StringVar(&graphFormat, "graph", "", "Print the Pipeline dependency graph (dot|mermaid)")
 
 	// Create
 	createCmd.AddCommand(createPipelineCmd)
 	createPipelineCmd.Flags().StringVarP(&importPath, "importPath", "", "", "YAML configuration file to import")
+	createPipelineCmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "Values file for .tmpl templates (can be repeated, later files take precedence)")
 	createPipelineCmd.Flags().StringVarP(&projectName, "project", "p", "", "Manually specify the Project in which to create the Pipeline (overrides YAML)")
 	createPipelineCmd.MarkFlagRequired("importPath")
 	// Update
 	updateCmd.AddCommand(updatePipelineCmd)
 	updatePipelineCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the pipeline to list")
 	updatePipelineCmd.Flags().StringVarP(&importPath, "importPath", "", "", "Configuration file to import")
+	updatePipelineCmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "Values file for .tmpl templates (can be repeated, later files take precedence)")
 	updatePipelineCmd.Flags().StringVarP(&state, "state", "s", "", "Set the state of the pipeline (ENABLED|DISABLED|RELEASED")
 	// Delete
 	deleteCmd.AddCommand(deletePipelineCmd)
 	deletePipelineCmd.Flags().StringVarP(&id, "id", "i", "", "ID of the Pipeline to delete")
 	deletePipelineCmd.Flags().StringVarP(&projectName, "project", "p", "", "Delete all Pipelines in the specified Project")
-
+	// Completions
+	registerCompletions(getPipelineCmd, "pipeline")
+	registerCompletions(updatePipelineCmd, "pipeline")
+	registerCompletions(deletePipelineCmd, "pipeline")
 }
@@ -0,0 +1,169 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/plugin"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// pluginCmd represents the plugin command
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage vra-cli plugins",
+	Long: `Manage vra-cli plugins
+
+Any executable on your PATH named vra-cli-<name> can be run as vra-cli <name>. The plugin receives the
+resolved session in its environment: VRA_CLI_TARGET, VRA_CLI_SERVER, VRA_CLI_ACCESS_TOKEN, VRA_CLI_OUTPUT,
+VRA_CLI_API_VERSION, VRA_CLI_DEBUG and VRA_CLI_INSECURE. VRA_SERVER and VRA_ACCESSTOKEN are also set, so
+the plugin can call vra-cli with the same session.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  func(cmd *cobra.Command, args []string) {},
+}
+
+// listPluginCmd represents the plugin list command
+var listPluginCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the vra-cli plugins on your PATH",
+	Long: `List the vra-cli plugins on your PATH
+
+# List plugins
+vra-cli plugin list`,
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		plugins := plugin.Find()
+		if len(plugins) == 0 {
+			log.Infoln("No plugins found on PATH")
+			return
+		}
+		if APIClient.Output == "json" {
+			helpers.PrettyPrint(plugins)
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Path"})
+		for _, p := range plugins {
+			table.Append([]string{p.Name, p.Path})
+		}
+		table.Render()
+		for _, p := range plugins {
+			if isBuiltinCommand(p.Name) {
+				log.Warnln("Plugin", p.Path, "is ignored - it has the same name as the built-in", p.Name, "command")
+			}
+			for _, shadowed := range p.Shadowed {
+				log.Warnln("Plugin", shadowed, "is ignored - it is shadowed by", p.Path)
+			}
+		}
+	},
+}
+
+// isBuiltinCommand returns true if name is a vra-cli command
+func isBuiltinCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Annotations["plugin"] == "" && (c.Name() == name || c.HasAlias(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addPluginCommands adds a command to run the plugin that the arguments call. The PATH is only searched
+// when the command is not built in, or when completing the command name, when every plugin is added.
+func addPluginCommands(args []string) {
+	positional := positionalArgs(args)
+	if len(positional) == 0 {
+		return
+	}
+	if positional[0] == cobra.ShellCompRequestCmd {
+		if len(positional) <= 2 {
+			for _, p := range plugin.Find() {
+				addPluginCommand(p)
+			}
+			return
+		}
+		positional = positional[1:]
+	}
+	name := positional[0]
+	if isBuiltinCommand(name) || isPluginCommand(name) {
+		return
+	}
+	if p := plugin.Lookup(name); p != nil {
+		addPluginCommand(p)
+	}
+}
+
+// positionalArgs returns the arguments that are not flags or flag values
+func positionalArgs(args []string) (positional []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(positional, args[i+1:]...)
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			positional = append(positional, arg)
+		case !strings.Contains(arg, "="):
+			var f *pflag.Flag
+			if strings.HasPrefix(arg, "--") {
+				f = rootCmd.PersistentFlags().Lookup(arg[2:])
+			} else if len(arg) == 2 {
+				f = rootCmd.PersistentFlags().ShorthandLookup(arg[1:])
+			}
+			if f != nil && f.NoOptDefVal == "" {
+				i++ // Skip the flag value
+			}
+		}
+	}
+	return positional
+}
+
+// isPluginCommand returns true if a command has already been added for the plugin name
+func isPluginCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Annotations["plugin"] != "" && c.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addPluginCommand adds a command to run a plugin, unless it has the name of a built-in command
+func addPluginCommand(p *plugin.Plugin) {
+	if isBuiltinCommand(p.Name) || isPluginCommand(p.Name) {
+		return
+	}
+	rootCmd.AddCommand(&cobra.Command{
+		Use:                p.Name,
+		Short:              "Plugin " + p.Path,
+		Annotations:        map[string]string{"plugin": p.Path},
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			tracing.Annotate("", tracing.CommandKey.String("plugin "+p.Name))
+			code, err := plugin.Run(APIClient, p, args)
+			if err != nil {
+				log.Fatalln("Unable to run plugin", p.Name+":", err)
+			}
+			if code != 0 {
+				if shellSession {
+					log.Warnln("Plugin", p.Name, "exited with status", code)
+					return
+				}
+				tracing.Shutdown()
+				os.Exit(code)
+			}
+		},
+	})
+}
+
+func init() {
+	pluginCmd.AddCommand(listPluginCmd)
+}
@@ -228,5 +228,8 @@ func init() {
 	deleteCmd.AddCommand(deleteProjectCommand)
 	deleteProjectCommand.Flags().StringVarP(&id, "id", "i", "", "ID of the Project to delete")
 	deleteProjectCommand.MarkFlagRequired("id")
-
+	// Completions
+	registerCompletions(getProjectCommand, "project")
+	registerCompletions(updateProjectCommand, "project")
+	registerCompletions(deleteProjectCommand, "project")
 }
@@ -141,4 +141,6 @@ func init() {
 	// createWorkflowCmd.Flags().StringVarP(&category, "category", "c", "", "Category to import")
 	// createWorkflowCmd.Flags().StringVar(&importPath, "importPath", "", "Path to the zip file, or folder containing zip files, to import")
 	// createWorkflowCmd.MarkFlagRequired("importPath")
+	// Completions
+	registerCompletions(getPropertyGroupCmd, "")
 }
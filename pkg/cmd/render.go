@@ -0,0 +1,95 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/template"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// valuesFiles - values files for Pipeline and Endpoint templates, in order of precedence
+var valuesFiles []string
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render Pipeline and Endpoint templates",
+	Long: `Render Pipeline and Endpoint templates with values files, to preview what create and update import.
+
+Files ending in .tmpl are Go templates (https://pkg.go.dev/text/template), rendered with the merged
+--values files as .Values before they are imported; later values files take precedence. Referencing a
+value that is not set is an error. The functions default, required, quote, toYaml and indent are also
+available:
+
+project: {{ .Values.project }}
+workspace:
+  endpoint: {{ .Values.endpoints.docker }}
+input:
+  replicas: {{ index .Values "replicas" | default 1 }}
+
+# Preview a Pipeline for production
+vra-cli render --importPath pipeline.yaml.tmpl --values prod.yaml
+
+# Create it
+vra-cli create pipeline --importPath pipeline.yaml.tmpl --values prod.yaml`,
+	Annotations: map[string]string{"offline": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		values, err := template.LoadValues(valuesFiles)
+		if err != nil {
+			log.Fatalln("Unable to load values:", err)
+		}
+		files := helpers.GetFilePaths(importPath, template.Extension)
+		if len(files) == 0 {
+			log.Warnln("No templates were found in", importPath)
+		}
+		for i, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			out, err := template.Render(file, content, values)
+			if err != nil {
+				log.Fatalln("Unable to render", err)
+			}
+			// Check that the output can be imported
+			if _, err := manifest.Parse(template.Name(file), out); err != nil {
+				log.Fatalln("Unable to parse rendered template:", err)
+			}
+			if i > 0 && !strings.HasPrefix(string(out), "---") {
+				fmt.Println("---")
+			}
+			fmt.Print(string(out))
+		}
+	},
+}
+
+// loadImportFile reads the documents in a file to import, rendering it with the --values files if it is a template
+func loadImportFile(path string, values map[string]interface{}) ([]*manifest.Document, error) {
+	if !template.IsTemplate(path) {
+		return manifest.LoadFile(path)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := template.Render(path, content, values)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Parse(template.Name(path), out)
+}
+
+func init() {
+	renderCmd.Flags().StringVar(&importPath, "importPath", "", "Template file, or folder of templates, to render")
+	renderCmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "Values file for templates (can be repeated, later files take precedence)")
+	renderCmd.MarkFlagRequired("importPath")
+}
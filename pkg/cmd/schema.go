@@ -0,0 +1,42 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonschema "github.com/sammcgeown/vra-cli/pkg/util/schema"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema " + strings.Join(jsonschema.Kinds, "|"),
+	Short: "Print the JSON Schema of a Code Stream YAML file",
+	Long: `Print the JSON Schema of Code Stream Pipeline, Endpoint, Variable or Custom Integration YAML,
+for validation and completion in editors. The Pipeline schema includes the supported task types and
+their inputs. lint pipeline and apply validate files against the same schemas.
+
+# Validate Pipelines in VS Code with the YAML extension, by adding to settings.json:
+#   "yaml.schemas": { "./pipeline.schema.json": "pipelines/*.yaml" }
+vra-cli schema pipeline > pipeline.schema.json`,
+	Annotations: map[string]string{"offline": "true"},
+	ValidArgs:   jsonschema.Kinds,
+	Args:        cobra.ExactValidArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := jsonschema.For(args[0])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		out, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(string(out))
+	},
+}
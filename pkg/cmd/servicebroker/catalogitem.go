@@ -33,12 +33,11 @@ func GetCatalogItems(APIClient *types.APIClientOptions, id string, name string,
 		WithExpandProjects(&expandProjects)
 
 	if project != "" {
-		Projects, err := cloudassembly.GetProject(APIClient, project, "")
+		ProjectID, err := cloudassembly.GetProjectID(APIClient, project)
 		if err != nil {
 			return nil, err
 		}
-		ProjectID := Projects[0].ID
-		CatalogItemParams.WithProjects([]string{*ProjectID})
+		CatalogItemParams.WithProjects([]string{ProjectID})
 	}
 	if name != "" {
 		CatalogItemParams.WithSearch(&name)
@@ -0,0 +1,305 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/mitchellh/go-homedir"
+	"github.com/sammcgeown/vra-cli/pkg/util/auth"
+	"github.com/sammcgeown/vra-cli/pkg/util/config"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	// shellSession is true while commands are being run from the interactive shell
+	shellSession bool
+	// shellProject is the default Project for the session
+	shellProject string
+	// shellArgs are the arguments of the command being run in the session
+	shellArgs []string
+)
+
+// shellExit is raised in place of os.Exit when a command calls log.Fatal inside the shell
+type shellExit struct{ code int }
+
+// shellCmd represents the shell command
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Start an interactive vra-cli shell",
+	Long: `Start an interactive shell that authenticates once and runs vra-cli commands against the same session.
+
+Commands are entered without the vra-cli prefix, and support line editing, history and tab completion.
+
+# Run any vra-cli command
+get pipeline --project "Field Demo"
+
+# Switch to another configured target
+use target my-other-vra
+
+# Use a default Project for commands with a --project flag, except delete (leave the name empty to clear it)
+use project "Field Demo"
+
+# Leave the shell
+exit`,
+	Run: func(cmd *cobra.Command, args []string) {
+		historyFile := ""
+		if home, err := homedir.Dir(); err == nil {
+			historyFile = filepath.Join(home, ".vra-cli_history")
+		}
+		rl, err := readline.NewEx(&readline.Config{
+			Prompt:          shellPrompt(),
+			HistoryFile:     historyFile,
+			AutoComplete:    shellCompleter{},
+			InterruptPrompt: "^C",
+			EOFPrompt:       "exit",
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer rl.Close()
+
+		// Stop log.Fatal in a command from ending the session
+		logger := log.StandardLogger()
+		exitFunc := logger.ExitFunc
+		logger.ExitFunc = func(code int) { panic(shellExit{code}) }
+		defer func() { logger.ExitFunc = exitFunc }()
+
+		// Each command in the session is traced as its own span
+		tracing.EndCommand()
+		shellSession = true
+		defer func() { shellSession = false }()
+		for {
+			line, err := rl.Readline()
+			if err == readline.ErrInterrupt {
+				continue
+			} else if err == io.EOF {
+				return
+			} else if err != nil {
+				log.Errorln(err)
+				return
+			}
+			args, err := helpers.SplitArgs(strings.TrimSpace(line))
+			if err != nil {
+				log.Errorln(err)
+				continue
+			}
+			if len(args) == 0 {
+				continue
+			}
+			switch args[0] {
+			case "exit", "quit":
+				return
+			case "use":
+				useInShell(args[1:])
+				rl.SetPrompt(shellPrompt())
+			case "shell":
+				log.Warnln("Already in a vra-cli shell")
+			default:
+				runInShell(args)
+			}
+		}
+	},
+}
+
+// shellPrompt returns the prompt showing the current target and project
+func shellPrompt() string {
+	context := targetConfig.Name
+	if shellProject != "" {
+		context += "/" + shellProject
+	}
+	return "vra-cli [" + context + "]> "
+}
+
+// useInShell switches the target or project used by the session
+func useInShell(args []string) {
+	if len(args) == 0 || len(args) > 2 || (args[0] != "target" && args[0] != "project") {
+		log.Errorln("Usage: use target <name> | use project [name]")
+		return
+	}
+	value := ""
+	if len(args) == 2 {
+		value = args[1]
+	}
+	switch args[0] {
+	case "target":
+		newConfig, err := config.GetTargetConfig(value)
+		if err != nil {
+			log.Errorln(err)
+			return
+		}
+		previousConfig := targetConfig
+		targetConfig = *newConfig
+		APIClient.Config = &targetConfig
+		if err := auth.ValidateConfiguration(APIClient); err != nil {
+			log.Errorln("Unable to use target", value+":", err)
+			targetConfig = previousConfig
+			if err := auth.ValidateConfiguration(APIClient); err != nil {
+				log.Errorln("Unable to restore target", targetConfig.Name+":", err)
+			}
+			return
+		}
+		tracing.Annotate("", tracing.TargetKey.String(targetConfig.Name), tracing.ServerKey.String(targetConfig.Server))
+		log.Infoln("Using target", value)
+	case "project":
+		shellProject = value
+	}
+}
+
+// applyShellProject sets --project to the session's Project, if the command being run has the flag and it
+// was not given. It runs once the flags are parsed. --project on a delete command deletes everything in the
+// Project, so it is never defaulted.
+func applyShellProject() {
+	if !shellSession || shellProject == "" {
+		return
+	}
+	cmd, _, err := rootCmd.Find(shellArgs)
+	if err != nil {
+		return
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == deleteCmd {
+			return
+		}
+	}
+	f := cmd.Flags().Lookup("project")
+	if f == nil || f.Changed || f.Value.Type() != "string" {
+		return
+	}
+	if err := cmd.Flags().Set("project", shellProject); err != nil {
+		log.Debugln("Unable to set flag project", err)
+	}
+}
+
+// runInShell runs a vra-cli command inside the session
+func runInShell(args []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(shellExit); !ok {
+				panic(r)
+			}
+		}
+		tracing.EndCommand()
+		resetFlags(rootCmd)
+		shellArgs = nil
+	}()
+	// Clear any query parameters left over from the previous command
+	APIClient.RESTClient.QueryParam = url.Values{}
+	APIClient.RESTClient.SetQueryParam("apiVersion", APIClient.Version)
+	APIClient.Summary = false
+	// Every --project flag shares projectName, so it is cleared before --project is parsed and defaulted
+	projectName = ""
+
+	addPluginCommands(args)
+	shellArgs = args
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		log.Debugln(err)
+	}
+}
+
+// shellCompleter - completes shell input using the cobra completion of the command tree
+type shellCompleter struct{}
+
+// Do implements readline.AutoCompleter
+func (shellCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	input := string(line[:pos])
+	args, err := helpers.SplitArgs(input)
+	if err != nil {
+		return nil, 0
+	}
+	toComplete := ""
+	if len(args) > 0 && !strings.HasSuffix(input, " ") {
+		toComplete = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	var candidates []string
+	if len(args) == 0 {
+		candidates = append(candidates, "exit", "use")
+	} else if args[0] == "use" {
+		if len(args) == 1 {
+			candidates = append(candidates, "target", "project")
+		}
+	}
+	if len(args) == 0 || args[0] != "use" {
+		candidates = append(candidates, cobraCompletions(args, toComplete)...)
+	}
+
+	var completions [][]rune
+	for _, candidate := range candidates {
+		if !strings.HasPrefix(candidate, toComplete) {
+			continue
+		}
+		suffix := candidate[len(toComplete):]
+		if toComplete == "" && strings.ContainsAny(candidate, " \t") {
+			suffix = strconv.Quote(candidate)
+		}
+		completions = append(completions, []rune(suffix+" "))
+	}
+	return completions, len([]rune(toComplete))
+}
+
+// cobraCompletions returns the completions cobra offers for the arguments
+func cobraCompletions(args []string, toComplete string) (completions []string) {
+	var out bytes.Buffer
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(shellExit); !ok {
+				panic(r)
+			}
+		}
+		rootCmd.SetOut(nil)
+		tracing.EndCommand()
+		resetFlags(rootCmd)
+	}()
+	rootCmd.SetOut(&out)
+	args = append(append([]string{cobra.ShellCompRequestCmd}, args...), toComplete)
+	addPluginCommands(args)
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, ":") { // The completion directive ends the list
+			break
+		}
+		if completion := strings.SplitN(line, "\t", 2)[0]; completion != "" {
+			completions = append(completions, completion)
+		}
+	}
+	return completions
+}
+
+// resetFlags restores the flags of a command tree to their defaults, so that
+// values from one shell command are not carried over to the next
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			s.Replace(nil)
+		} else if err := f.Value.Set(f.DefValue); err != nil {
+			log.Debugln("Unable to reset flag", f.Name, err)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
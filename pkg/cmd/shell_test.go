@@ -0,0 +1,63 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/spf13/cobra"
+)
+
+func TestShellProject(t *testing.T) {
+	restClient, session, project := APIClient.RESTClient, shellSession, shellProject
+	getRun, deleteRun := getPipelineCmd.Run, deletePipelineCmd.Run
+	t.Cleanup(func() {
+		APIClient.RESTClient, shellSession, shellProject = restClient, session, project
+		getPipelineCmd.Run, deletePipelineCmd.Run = getRun, deleteRun
+		projectName = ""
+	})
+	APIClient.RESTClient = resty.New()
+	shellSession = true
+
+	var got string
+	getPipelineCmd.Run = func(cmd *cobra.Command, args []string) { got = projectName }
+	deletePipelineCmd.Run = func(cmd *cobra.Command, args []string) { got = projectName }
+
+	useInShell([]string{"project", "Field Demo"})
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "default", args: []string{"get", "pipeline"}, want: "Field Demo"},
+		{name: "given", args: []string{"get", "pipeline", "--project", "Other"}, want: "Other"},
+		{name: "delete", args: []string{"delete", "pipeline"}, want: ""},
+		{name: "delete by ID", args: []string{"delete", "pipeline", "--id", "71dcc4aa-fa43-4e66-a9c6-4d1d6da2a4ab"}, want: ""},
+		{name: "delete given", args: []string{"delete", "pipeline", "--project", "Other"}, want: "Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = "not run"
+			runInShell(tt.args)
+			if got != tt.want {
+				t.Errorf("runInShell(%q) ran with --project %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+
+	// A delete straight after a command that used the session's Project
+	runInShell([]string{"get", "pipeline"})
+	runInShell([]string{"delete", "pipeline"})
+	if got != "" {
+		t.Errorf("delete after get ran with --project %q, want none", got)
+	}
+
+	useInShell([]string{"project"})
+	runInShell([]string{"get", "pipeline"})
+	if got != "" {
+		t.Errorf("get after clearing the Project ran with --project %q, want none", got)
+	}
+}
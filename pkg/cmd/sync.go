@@ -0,0 +1,226 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/gitsync"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncRepo     string
+	syncPrune    bool
+	syncWatch    bool
+	syncInterval time.Duration
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Apply the manifests in a git repository",
+	Long: `Apply the manifests in a local git working tree (see vra-cli apply), updating only the objects whose
+manifests, or referenced files, changed since the commit last synced to the current target. The first sync
+of a target applies every manifest.
+
+The last synced commit for each target is recorded in the repository's .git directory, and is only
+updated when every object applies successfully. The working tree must not have uncommitted changes,
+unless --force is set.
+
+With --prune, objects whose manifests were removed since the last sync are deleted. vRO Workflows, Actions
+and Packages are never deleted.
+
+# Sync a repository to the current target
+vra-cli sync --repo ./content
+
+# Keep the current target in sync with the repository, checking for new commits every minute
+vra-cli sync --repo ./content --prune --watch --interval 1m`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := filepath.Abs(syncRepo)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if !syncWatch {
+			if err := syncOnce(path); err != nil {
+				log.Fatalln(err)
+			}
+			return
+		}
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+		log.Infoln("Watching", path, "every", syncInterval, "- press Ctrl-C to stop")
+		for {
+			if err := syncOnce(path); err != nil {
+				log.Errorln(err)
+			}
+			select {
+			case <-ticker.C:
+			case <-interrupt:
+				log.Infoln("Stopped watching", path)
+				return
+			}
+		}
+	},
+}
+
+// syncOnce applies the manifests changed since the last synced commit, and records the new commit
+func syncOnce(path string) error {
+	repo, err := gitsync.Open(path)
+	if err != nil {
+		return err
+	}
+	if clean, err := repo.Clean(); err != nil {
+		return err
+	} else if !clean && !APIClient.Force {
+		return errors.New(repo.Root + " has uncommitted changes - commit them, or use --force")
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	target := APIClient.Config.Name
+	state, err := repo.ReadState(target)
+	if err != nil {
+		return err
+	}
+	if state.Commit == head {
+		log.Debugln(target, "is up to date with", head)
+		return nil
+	}
+	changes, err := repo.Changes(state.Commit, head)
+	if err != nil {
+		return err
+	}
+	if changes.Full && state.Commit != "" {
+		log.Warnln("The last synced commit", state.Commit, "is not in the repository, syncing every manifest")
+	}
+
+	documents, err := manifest.LoadTree(path)
+	if err != nil {
+		return err
+	}
+	apply := documents
+	if !changes.Full {
+		changed := make(map[string]bool)
+		for _, file := range changes.Changed {
+			changed[filepath.Clean(file)] = true
+		}
+		apply = nil
+		for _, document := range documents {
+			if changed[filepath.Clean(document.Source)] || (document.File("file") != "" && changed[filepath.Clean(document.File("file"))]) {
+				apply = append(apply, document)
+			}
+		}
+	}
+
+	var remove []*manifest.Document
+	if syncPrune {
+		if changes.Full {
+			log.Warnln("Nothing is pruned without a previous sync of", target)
+		} else if remove, err = removedDocuments(path, documents, changes.Previous); err != nil {
+			return err
+		}
+	}
+	if len(apply) == 0 && len(remove) == 0 {
+		log.Infoln("No manifests changed between", shortCommit(state.Commit), "and", shortCommit(head))
+	} else {
+		log.Infoln("Syncing", len(apply), "changed and", len(remove), "removed objects from", shortCommit(head), "to", target)
+		results, failed, err := runDocuments(apply, nil)
+		if err != nil {
+			return err
+		}
+		for _, result := range deleteDocuments(remove) {
+			if result.Result == "failed" {
+				failed++
+			}
+			results = append(results, result)
+		}
+		printApplyResults(results)
+		if failed > 0 {
+			return errors.New(strconv.Itoa(failed) + " of " + strconv.Itoa(len(results)) + " objects failed to sync, " + target + " remains at " + shortCommit(state.Commit))
+		}
+	}
+	if APIClient.DryRun {
+		return nil
+	}
+	return repo.WriteState(target, head)
+}
+
+// removedDocuments returns the documents in the previous versions of changed files that are no longer in the repository
+func removedDocuments(path string, documents []*manifest.Document, previous []gitsync.File) ([]*manifest.Document, error) {
+	current := make(map[string]bool)
+	for _, document := range documents {
+		current[documentKey(document)] = true
+	}
+	var removed []*manifest.Document
+	for _, file := range previous {
+		if !strings.HasPrefix(file.Path, path+string(os.PathSeparator)) {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(file.Path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		fileDocuments, err := manifest.Parse(file.Path, file.Content)
+		if errors.Is(err, manifest.ErrNotManifest) {
+			continue
+		} else if err != nil {
+			log.Warnln("Unable to read the previous version of", file.Path+":", err)
+			continue
+		}
+		for _, document := range fileDocuments {
+			if current[documentKey(document)] {
+				continue
+			}
+			switch document.Kind {
+			case manifest.KindWorkflow, manifest.KindAction, manifest.KindPackage:
+				log.Warnln(document, "was removed, but vRO objects are not pruned")
+				continue
+			}
+			current[documentKey(document)] = true
+			removed = append(removed, document)
+		}
+	}
+	return removed, manifest.Sort(removed)
+}
+
+// documentKey identifies the object a document describes
+func documentKey(document *manifest.Document) string {
+	return document.Kind + "/" + document.Project + "/" + document.Name
+}
+
+// shortCommit abbreviates a commit hash for logging
+func shortCommit(commit string) string {
+	if commit == "" {
+		return "(none)"
+	}
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncRepo, "repo", "", "Git working tree, or a folder in one, containing the manifests")
+	syncCmd.Flags().BoolVar(&syncPrune, "prune", false, "Delete objects whose manifests were removed")
+	syncCmd.Flags().BoolVar(&syncWatch, "watch", false, "Keep syncing new commits until interrupted")
+	syncCmd.Flags().DurationVar(&syncInterval, "interval", 30*time.Second, "How often to check for new commits with --watch")
+	syncCmd.MarkFlagRequired("repo")
+}
@@ -8,7 +8,10 @@ import (
 	"os"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/codestream"
+	"github.com/sammcgeown/vra-cli/pkg/util/conflict"
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
 
 	log "github.com/sirupsen/logrus"
 
@@ -43,9 +46,13 @@ vra-cli get variable --project production`,
 		if APIClient.Output == "json" {
 			helpers.PrettyPrint(response[0])
 		} else if APIClient.Output == "export" {
-			for _, c := range response {
-				codestream.ExportVariable(c, exportPath)
+			names := make([]string, len(response))
+			for i, c := range response {
+				names[i] = c.Name
 			}
+			exportObjects(names, "Variables", func(client *types.APIClientOptions, i int) error {
+				return codestream.ExportVariable(client, response[i], exportPath)
+			})
 		} else {
 			// Print result table
 			table := tablewriter.NewWriter(os.Stdout)
@@ -69,18 +76,7 @@ var createVariableCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if importPath != "" { // If we are importing a file
-			variables := codestream.ImportVariables(importPath)
-			for _, value := range variables {
-				if projectName != "" { // If the project is specified update the object
-					value.Project = projectName
-				}
-				createResponse, err := codestream.CreateVariable(APIClient, value.Name, value.Description, value.Type, value.Project, value.Value)
-				if err != nil {
-					log.Warnln("Unable to create Code Stream Variable: ", err)
-				} else {
-					log.Infoln("Created variable", createResponse.Name, "in", createResponse.Project)
-				}
-			}
+			reportImport(importDocuments(variableDocuments(importPath, projectName), onConflict(conflict.Fail)))
 		} else {
 			createResponse, err := codestream.CreateVariable(APIClient, name, description, typename, projectName, value)
 			if err != nil {
@@ -108,20 +104,7 @@ var updateVariableCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if importPath != "" { // If we are importing a file
-			variables := codestream.ImportVariables(importPath)
-			for _, value := range variables {
-				exisitingVariable, err := codestream.GetVariable(APIClient, "", value.Name, value.Project, "")
-				if err != nil {
-					log.Errorln("Update failed - unable to find existing Code Stream Variable", value.Name, "in", value.Project)
-				} else {
-					_, err := codestream.UpdateVariable(APIClient, exisitingVariable[0].ID, value.Name, value.Description, value.Type, value.Value)
-					if err != nil {
-						log.Errorln("Unable to update Code Stream Variable: ", err)
-					} else {
-						log.Infoln("Updated variable", value.Name)
-					}
-				}
-			}
+			reportImport(importDocuments(variableDocuments(importPath, ""), onConflict(conflict.Overwrite)))
 		} else { // Else we are updating using flags
 			updateResponse, err := codestream.UpdateVariable(APIClient, id, name, description, typename, value)
 			if err != nil {
@@ -173,12 +156,28 @@ vra-cli delete variable --project "My Project"
 			if err != nil {
 				log.Errorln("Delete Variables in "+projectName+" failed:", err)
 			} else {
-				log.Infoln(len(response), "Variables deleted")
+				reportDeleted(response, "Variables")
 			}
 		}
 	},
 }
 
+// variableDocuments returns the Variables in the import path as documents, moving them to project if it is set
+func variableDocuments(importPath, project string) []*manifest.Document {
+	var documents []*manifest.Document
+	for _, variable := range codestream.ImportVariables(importPath) {
+		if project != "" {
+			variable.Project = project
+		}
+		document, err := manifest.New(manifest.KindVariable, importPath, variable)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
 func init() {
 	// Get Variable
 	getCmd.AddCommand(GetVariableCmd)
@@ -209,4 +208,8 @@ func init() {
 	deleteCmd.AddCommand(deleteVariableCmd)
 	deleteVariableCmd.Flags().StringVarP(&id, "id", "i", "", "Delete variable by id")
 	deleteVariableCmd.Flags().StringVarP(&projectName, "project", "p", "", "The project in which to delete the variable, or delete all variables in project")
+	// Completions
+	registerCompletions(GetVariableCmd, "variable")
+	registerCompletions(updateVariableCmd, "variable")
+	registerCompletions(deleteVariableCmd, "variable")
 }
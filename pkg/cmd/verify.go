@@ -0,0 +1,140 @@
+/*
+Package cmd Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/integrity"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// verifyResult - the integrity of an exported file
+type verifyResult struct {
+	integrity.FileResult
+	Signature *integrity.Signature `json:"signature"`
+}
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the checksums and signatures of exported files",
+	Long: `Verify exported files against the SHA256SUMS checksum manifest written alongside them, and the
+SHA256SUMS.sig signature when exports are signed with --signingKey. Signatures are checked against the
+public keys trusted with vra-cli key import.
+
+The exit code is 1 when a file has been modified or is missing, or a signature is not valid. With
+--integrity signed, unsigned files also fail.
+
+# Verify a folder of exports, and any folders in it
+vra-cli verify --importPath ./exports
+
+# Verify a single export
+vra-cli verify --importPath "./exports/My Pipeline.yaml"`,
+	Annotations: map[string]string{"offline": "true"},
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		results, err := verifyPath(importPath)
+		if err != nil {
+			log.Fatalln("Unable to verify", importPath+":", err)
+		}
+		if len(results) == 0 {
+			log.Fatalln("No", integrity.SumsFile, "checksum manifests were found in", importPath)
+		}
+
+		failed := 0
+		for _, r := range results {
+			switch {
+			case r.Status == integrity.StatusModified || r.Status == integrity.StatusMissing:
+				failed++
+			case r.Status == integrity.StatusUntracked:
+				log.Warnln(r.File, "is not in the checksum manifest")
+			case r.Signature.Signed && !r.Signature.Valid:
+				failed++
+			case !r.Signature.Signed && APIClient.Integrity == integrity.PolicySigned:
+				failed++
+			}
+		}
+
+		if APIClient.Output == "json" {
+			helpers.PrettyPrint(results)
+		} else {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"File", "Checksum", "Signature"})
+			for _, r := range results {
+				table.Append([]string{r.File, r.Status, signatureStatus(r.Signature)})
+			}
+			table.Render()
+		}
+		if failed > 0 {
+			log.Errorln(failed, "of", len(results), "files failed verification")
+			if !shellSession {
+				tracing.Shutdown()
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// verifyPath verifies a file, or the files in every directory under a path that has a checksum manifest
+func verifyPath(path string) ([]verifyResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		dir := filepath.Dir(path)
+		results, err := integrity.VerifyDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		signature := integrity.VerifySignature(dir)
+		for _, r := range results {
+			if r.File == filepath.Join(dir, filepath.Base(path)) {
+				return []verifyResult{{FileResult: r, Signature: signature}}, nil
+			}
+		}
+		return []verifyResult{{FileResult: integrity.FileResult{File: path, Status: integrity.StatusUntracked}, Signature: signature}}, nil
+	}
+
+	var results []verifyResult
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Name() != integrity.SumsFile {
+			return err
+		}
+		dir := filepath.Dir(file)
+		dirResults, err := integrity.VerifyDir(dir)
+		if err != nil {
+			return err
+		}
+		signature := integrity.VerifySignature(dir)
+		for _, r := range dirResults {
+			results = append(results, verifyResult{FileResult: r, Signature: signature})
+		}
+		return nil
+	})
+	return results, err
+}
+
+// signatureStatus describes a signature for the results table
+func signatureStatus(s *integrity.Signature) string {
+	switch {
+	case !s.Signed:
+		return "unsigned"
+	case s.Valid:
+		return "valid (" + s.Key + ")"
+	}
+	return "invalid - " + s.Error
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&importPath, "importPath", "", "Exported file, or folder of exports, to verify")
+	verifyCmd.MarkFlagRequired("importPath")
+}
@@ -6,11 +6,11 @@ package cmd
 
 import (
 	"os"
-	"strings"
 
 	"github.com/sammcgeown/vra-cli/pkg/cmd/orchestrator"
-
 	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+	"github.com/sammcgeown/vra-cli/pkg/util/manifest"
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/olekukonko/tablewriter"
@@ -30,7 +30,8 @@ vra-cli get workflow --id bb3f6aff-311a-45fe-8081-5845a529068d
 # Get Failed workflows in Project "Field Demo" with the name "Learn Code Stream"
 vra-cli get workflow --status FAILED --project "Field Demo" --name "Learn Code Stream"`,
 	Run: func(cmd *cobra.Command, args []string) {
-
+		// The table only needs the fields returned in the workflow list
+		APIClient.Summary = APIClient.Output == "table"
 		response, err := orchestrator.GetWorkflow(APIClient, id, category, name)
 		if err != nil {
 			log.Errorln("Unable to get workflows: ", err)
@@ -44,21 +45,27 @@ vra-cli get workflow --status FAILED --project "Field Demo" --name "Learn Code S
 				// Print result table
 				table := tablewriter.NewWriter(os.Stdout)
 				table.SetHeader([]string{"Id", "Name", "Version", "Description", "Category"})
+				categoryPaths := make(map[string]string)
 				for _, c := range response {
-					category, _ := orchestrator.GetCategoryByID(APIClient, c.CategoryID)
-					table.Append([]string{c.ID, c.Name, c.Version, c.Description, category.Path})
+					if _, ok := categoryPaths[c.CategoryID]; !ok {
+						if category, err := orchestrator.GetCategoryByID(APIClient, c.CategoryID); err == nil {
+							categoryPaths[c.CategoryID] = category.Path
+						} else {
+							categoryPaths[c.CategoryID] = ""
+						}
+					}
+					table.Append([]string{c.ID, c.Name, c.Version, c.Description, categoryPaths[c.CategoryID]})
 				}
 				table.Render()
 			} else if APIClient.Output == "export" {
-				// Export the Worfklow
-				for _, workflow := range response {
-					err := orchestrator.ExportWorkflow(APIClient, workflow.ID, workflow.Name, exportPath)
-					if err != nil {
-						log.Warnln("Unable to export workflow: ", err)
-					} else {
-						log.Infoln("Workflow", workflow.Name, "exported")
-					}
+				// Export the Worfklows
+				names := make([]string, len(response))
+				for i, workflow := range response {
+					names[i] = workflow.Name
 				}
+				exportObjects(names, "Workflows", func(client *types.APIClientOptions, i int) error {
+					return orchestrator.ExportWorkflow(client, response[i].ID, response[i].Name, exportPath)
+				})
 
 			} else {
 				helpers.PrettyPrint(response)
@@ -91,40 +98,14 @@ var createWorkflowCmd = &cobra.Command{
 	Long:  `Create a Workflow`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get the category ID
-		var CategoryID string
-		categoryName := (strings.Split(category, "/"))[len(strings.Split(category, "/"))-1]
-		categories, _ := orchestrator.GetCategoryByName(APIClient, categoryName, "WorkflowCategory")
-		if len(categories) == 0 {
-			log.Fatalln("Unable to find category:", categoryName)
-		} else if len(categories) == 1 {
-			// Only one category found
-			log.Debugln("Category found:", categories[0].Name, categories[0].ID)
-			CategoryID = categories[0].ID
-		} else {
-			for _, matchedCategory := range categories {
-				if matchedCategory.Path == category {
-					log.Debugln("Category ID:", matchedCategory.ID)
-					CategoryID = matchedCategory.ID
-					break
-				}
-			}
-			if CategoryID == "" {
-				log.Fatalln("Multiple categories found, try using a more specific category - e.g.: path/to/category")
-			}
-		}
-		for _, path := range helpers.GetFilePaths(importPath, ".zip") {
-			log.Infoln("Importing workflow:", path)
-			err := orchestrator.ImportWorkflow(APIClient, path, CategoryID)
-			if err != nil {
-				log.Errorln("Unable to import workflow: ", err)
-			} else {
-				workflow, err := orchestrator.GetWorkflow(APIClient, "", categoryName, name)
-				if err != nil {
-					log.Errorln("Workflow imported OK, but I'm unable to get imported workflow details: ", err)
-				}
-				log.Infoln("Workflow imported:", workflow[0].Name, "with ID:", workflow[0].ID)
-			}
+		CategoryID, err := orchestrator.GetCategoryID(APIClient, category, "WorkflowCategory")
+		if err != nil {
+			log.Fatalln(err)
 		}
+		paths := helpers.GetFilePaths(importPath, ".zip")
+		reportImport(importOrchestratorPaths(manifest.KindWorkflow, paths, orchestratorPolicy(), func(APIClient *types.APIClientOptions, path string) error {
+			return orchestrator.ImportWorkflow(APIClient, path, CategoryID)
+		}))
 
 	},
 }
@@ -145,4 +126,7 @@ func init() {
 	createWorkflowCmd.Flags().StringVarP(&category, "category", "c", "", "Category to import")
 	createWorkflowCmd.Flags().StringVar(&importPath, "importPath", "", "Path to the zip file, or folder containing zip files, to import")
 	createWorkflowCmd.MarkFlagRequired("importPath")
+	// Completions
+	registerCompletions(getWorkflowCmd, "workflow")
+	registerCompletions(delWorkflowCmd, "workflow")
 }
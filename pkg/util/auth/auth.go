@@ -11,6 +11,8 @@ import (
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-resty/resty/v2"
+	"github.com/sammcgeown/vra-cli/pkg/util/dryrun"
+	"github.com/sammcgeown/vra-cli/pkg/util/tracing"
 	"github.com/sammcgeown/vra-cli/pkg/util/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -18,11 +20,17 @@ import (
 )
 
 // ValidateConfiguration - returns a connection to vRA
-func ValidateConfiguration(APIClient *types.APIClientOptions) error {
+func ValidateConfiguration(APIClient *types.APIClientOptions) (err error) {
+	ctx, span := tracing.Start(tracing.Context(), "auth.ValidateConfiguration",
+		tracing.TargetKey.String(APIClient.Config.Name),
+		tracing.ServerKey.String(APIClient.Config.Server))
+	defer func() { tracing.End(span, err) }()
+
 	// Get a Resty client
 	APIClient.RESTClient = GetRESTClient(APIClient.Config, APIClient.Version, APIClient.VerifySSL, APIClient.Debug)
 	// Query the API to see if we're authenticated
 	queryResponse, err := APIClient.RESTClient.R().
+		SetContext(ctx).
 		SetResult(&types.UserPreferences{}).
 		SetError(&types.Exception{}).
 		Get("/pipeline/api/user-preferences")
@@ -36,6 +44,7 @@ func ValidateConfiguration(APIClient *types.APIClientOptions) error {
 	if queryResponse.StatusCode() == 401 {
 		log.Debug("Attempting to authenticate the existing API Refresh Token")
 		queryResponse, _ := APIClient.RESTClient.R().
+			SetContext(ctx).
 			SetBody(types.Authentication{RefreshToken: APIClient.Config.APIToken}).
 			SetResult(&types.AuthenticationResponse{}).
 			SetError(&types.AuthenticationError{}).
@@ -44,6 +53,7 @@ func ValidateConfiguration(APIClient *types.APIClientOptions) error {
 		// If we get an error response, we have an expired token
 		if queryResponse.IsError() {
 			log.Debugln("Refresh Token failed", queryResponse.Error().(*types.AuthenticationError).Message)
+			span.AddEvent("refresh token rejected")
 			// If it's vRA Cloud we have no credentials to authenticate
 			if APIClient.Config.Server == "api.mgmt.cloud.vmware.com" {
 				// Return the token error
@@ -66,6 +76,7 @@ func ValidateConfiguration(APIClient *types.APIClientOptions) error {
 			}
 
 			loginResponse, _ := APIClient.RESTClient.R().
+				SetContext(ctx).
 				SetBody(authBody).
 				SetResult(&types.AuthenticationResponse{}).
 				SetError(&types.AuthenticationError{}).
@@ -79,6 +90,7 @@ func ValidateConfiguration(APIClient *types.APIClientOptions) error {
 
 			// Authenticate with the IaaS API
 			queryResponse, _ := APIClient.RESTClient.R().
+				SetContext(ctx).
 				SetBody(types.Authentication{RefreshToken: APIClient.Config.APIToken}).
 				SetResult(&types.AuthenticationResponse{}).
 				SetError(&types.AuthenticationError{}).
@@ -88,6 +100,7 @@ func ValidateConfiguration(APIClient *types.APIClientOptions) error {
 			}
 			APIClient.Config.AccessToken = queryResponse.Result().(*types.AuthenticationResponse).Token
 			log.Debugln("Authentication succeeded")
+			span.AddEvent("authenticated with credentials")
 			// Else we have a valid token
 		} else {
 			log.Debug("Refresh Token succeeded")
@@ -113,6 +126,7 @@ func ValidateConfiguration(APIClient *types.APIClientOptions) error {
 func GetAPIClient(config *types.Config, debug bool) *client.MulticloudIaaS {
 	transport := httptransport.New(config.Server, "", nil)
 	transport.SetDebug(debug)
+	transport.Transport = tracing.Transport(dryrun.Transport(transport.Transport))
 	transport.DefaultAuthentication = httptransport.APIKeyAuth("Authorization", "header", "Bearer "+config.AccessToken)
 	apiclient := client.New(transport, strfmt.Default)
 	return apiclient
@@ -130,5 +144,25 @@ func GetRESTClient(config *types.Config, apiVersion string, insecure bool, debug
 		SetHeader("Content-Type", "application/json").
 		SetError(&types.Exception{}).
 		SetQueryParam("apiVersion", apiVersion)
+	// Record every request as a span when tracing is enabled, and hold back changes in dry-run mode
+	client.SetTransport(tracing.Transport(dryrun.Transport(client.GetClient().Transport)))
 	return client
 }
+
+// Clone returns a copy of the API client with its own REST client, for a concurrent worker. The
+// REST client's query parameters are shared by all of its requests, so it cannot be used concurrently.
+func Clone(APIClient *types.APIClientOptions) *types.APIClientOptions {
+	clone := *APIClient
+	clone.RESTClient = GetRESTClient(APIClient.Config, APIClient.Version, APIClient.VerifySSL, APIClient.Debug)
+	return &clone
+}
+
+// Clients returns an API client for each worker of a batch of count items. The first worker uses
+// APIClient, and the others a clone with their own REST client.
+func Clients(APIClient *types.APIClientOptions, count int) []*types.APIClientOptions {
+	clients := []*types.APIClientOptions{APIClient}
+	for len(clients) < APIClient.Parallel && len(clients) < count {
+		clients = append(clients, Clone(APIClient))
+	}
+	return clients
+}
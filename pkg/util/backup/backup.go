@@ -0,0 +1,214 @@
+/*
+Package backup Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFile is the name of the manifest at the root of a backup
+const ManifestFile = "manifest.json"
+
+// FormatVersion is the version of the backup layout
+const FormatVersion = 1
+
+// Manifest - describes the contents of a backup
+type Manifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	Version       string    `json:"vraCliVersion"`
+	Target        string    `json:"target"`
+	Server        string    `json:"server"`
+	Project       string    `json:"project"`
+	Created       time.Time `json:"created"`
+	Objects       []Object  `json:"objects"`
+	Skipped       []Object  `json:"skipped,omitempty"` // Objects that could not be backed up
+}
+
+// Object - an object in a backup
+type Object struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Project string `json:"project,omitempty"`
+	File    string `json:"file,omitempty"`   // Manifest document, relative to the backup root
+	Reason  string `json:"reason,omitempty"` // Why the object was skipped
+}
+
+// Kinds returns the number of objects of each kind in the backup
+func (m *Manifest) Kinds() map[string]int {
+	kinds := make(map[string]int)
+	for _, o := range m.Objects {
+		kinds[o.Kind]++
+	}
+	return kinds
+}
+
+// Files returns the distinct manifest documents in the backup, in the order they were added
+func (m *Manifest) Files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, o := range m.Objects {
+		if !seen[o.File] {
+			seen[o.File] = true
+			files = append(files, o.File)
+		}
+	}
+	return files
+}
+
+// IsArchive returns true if the path is a gzipped tarball
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// WriteManifest writes the manifest to the root of the backup directory
+func WriteManifest(dir string, m *Manifest) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ManifestFile), content, 0644)
+}
+
+// ReadManifest reads the manifest from the root of the backup directory
+func ReadManifest(dir string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	if m.FormatVersion > FormatVersion {
+		return nil, errors.New("the backup was written by a newer version of vra-cli")
+	}
+	return &m, nil
+}
+
+// Archive writes the contents of dir to a gzipped tarball
+func Archive(dir, file string) (err error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		content, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		_, err = io.Copy(tw, content)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Path returns the path of the named file in the backup directory, or an error if it is outside the directory
+func Path(dir, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if !Within(dir, target) {
+		return "", errors.New("invalid path in backup: " + name)
+	}
+	return target, nil
+}
+
+// Within returns true if the path is inside the backup directory
+func Within(dir, path string) bool {
+	return strings.HasPrefix(filepath.Clean(path), filepath.Clean(dir)+string(os.PathSeparator))
+}
+
+// Extract unpacks a gzipped tarball into dir
+func Extract(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		target, err := Path(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// FileName returns a name that is safe to use as a file name
+func FileName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+}
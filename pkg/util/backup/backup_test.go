@@ -0,0 +1,88 @@
+/*
+Package backup Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "source")
+	os.MkdirAll(filepath.Join(source, "pipelines"), 0755)
+	ioutil.WriteFile(filepath.Join(source, "pipelines", "build.yaml"), []byte("kind: PIPELINE\nname: build\n"), 0644)
+	m := &Manifest{FormatVersion: FormatVersion, Project: "Demo", Objects: []Object{
+		{Kind: "Pipeline", Name: "build", Project: "Demo", File: "pipelines/build.yaml"},
+	}}
+	if err := WriteManifest(source, m); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(dir, "backup.tar.gz")
+	if !IsArchive(archive) {
+		t.Fatal("expected", archive, "to be an archive")
+	}
+	if err := Archive(source, archive); err != nil {
+		t.Fatal(err)
+	}
+	restored := filepath.Join(dir, "restored")
+	if err := Extract(archive, restored); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadManifest(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Project != "Demo" || len(got.Files()) != 1 || got.Kinds()["Pipeline"] != 1 {
+		t.Errorf("unexpected manifest %+v", got)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(restored, "pipelines", "build.yaml"))
+	if err != nil || string(content) != "kind: PIPELINE\nname: build\n" {
+		t.Errorf("unexpected content %q %v", content, err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := filepath.Join("backups", "field-demo")
+	tests := []struct {
+		name string
+		want string
+		err  bool
+	}{
+		{name: "project.json", want: filepath.Join(dir, "project.json")},
+		{name: "pipelines/deploy.yaml", want: filepath.Join(dir, "pipelines", "deploy.yaml")},
+		{name: "pipelines/../project.json", want: filepath.Join(dir, "project.json")},
+		{name: "../field-demo-2/project.json", err: true},
+		{name: "../../etc/passwd", err: true},
+		{name: "..", err: true},
+		{name: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Path(dir, tt.name)
+			if tt.err {
+				if err == nil {
+					t.Errorf("Path(%q) = %s, want an error", tt.name, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Path(%q) = %s, %v, want %s", tt.name, got, err, tt.want)
+			}
+		})
+	}
+	if Within(dir, "/etc/passwd") {
+		t.Error("Within() of an absolute path outside the backup should be false")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Package batch Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+	"github.com/olekukonko/tablewriter"
+	"github.com/sammcgeown/vra-cli/pkg/util/helpers"
+)
+
+// Failed is the status of an item whose operation returned an error
+const Failed = "failed"
+
+// Progress is where the progress of batches of more than one item is reported, as a bar when it is
+// a terminal and a line per item otherwise
+var Progress io.Writer = os.Stderr
+
+// Result - the outcome of an operation on a single item
+type Result struct {
+	Name   string `json:"name"`
+	Status string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Run calls fn for each of the named items using at most parallel workers and
+// returns the results in the order of names. fn is given the index of the worker
+// calling it, so that callers can keep a client per worker, and returns the
+// status of the item, or an error.
+func Run(action string, names []string, parallel int, fn func(worker, i int) (string, error)) []Result {
+	results := make([]Result, len(names))
+	if len(names) == 0 {
+		return results
+	}
+
+	bar := newProgress(action, len(names))
+	helpers.ForEachWorker(len(names), parallel, func(worker, i int) error {
+		status, err := fn(worker, i)
+		results[i] = Result{Name: names[i], Status: status}
+		if err != nil {
+			results[i].Status = Failed
+			results[i].Error = err.Error()
+		}
+		bar.done(results[i])
+		return err
+	})
+	bar.finish()
+	return results
+}
+
+// Failures returns the number of results that failed
+func Failures(results []Result) int {
+	var failed int
+	for _, result := range results {
+		if result.Status == Failed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Summary returns the number of results with each status, e.g. "3 deleted, 1 failed"
+func Summary(results []Result) string {
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	var statuses []string
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	var summary []string
+	for _, status := range statuses {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+	}
+	return strings.Join(summary, ", ")
+}
+
+// Print writes the per item results and the summary in the output format
+func Print(w io.Writer, results []Result, output string) {
+	if output == "json" {
+		out, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Fprintln(w, string(out))
+		return
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Name", "Result", "Error"})
+	for _, result := range results {
+		table.Append([]string{result.Name, result.Status, result.Error})
+	}
+	table.SetFooter([]string{"", Summary(results), ""})
+	table.Render()
+}
+
+// progress reports the completed items of a batch
+type progress struct {
+	sync.Mutex
+	action   string
+	total    int
+	complete int
+	failed   int
+	terminal bool
+	quiet    bool
+}
+
+func newProgress(action string, total int) *progress {
+	p := &progress{action: action, total: total, quiet: total < 2}
+	if f, ok := Progress.(*os.File); ok {
+		p.terminal = isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return p
+}
+
+func (p *progress) done(result Result) {
+	p.Lock()
+	defer p.Unlock()
+	p.complete++
+	if result.Status == Failed {
+		p.failed++
+	}
+	if p.quiet {
+		return
+	}
+	if !p.terminal {
+		status := result.Status
+		if result.Error != "" {
+			status += ": " + result.Error
+		}
+		fmt.Fprintf(Progress, "[%d/%d] %s %s - %s\n", p.complete, p.total, p.action, result.Name, status)
+		return
+	}
+	const width = 30
+	filled := width * p.complete / p.total
+	fmt.Fprintf(Progress, "\r%s [%s%s] %d/%d", p.action, strings.Repeat("=", filled), strings.Repeat(" ", width-filled), p.complete, p.total)
+	if p.failed > 0 {
+		fmt.Fprintf(Progress, " (%d failed)", p.failed)
+	}
+}
+
+func (p *progress) finish() {
+	if p.terminal && !p.quiet {
+		fmt.Fprintln(Progress)
+	}
+}
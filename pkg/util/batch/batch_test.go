@@ -0,0 +1,52 @@
+/*
+Package batch Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	saved := Progress
+	t.Cleanup(func() { Progress = saved })
+	Progress = &out
+	var running, peak int32
+	names := []string{"a", "b", "c", "d", "e"}
+	results := Run("Deleting", names, 2, func(worker, i int) (string, error) {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		defer atomic.AddInt32(&running, -1)
+		if worker < 0 || worker > 1 {
+			t.Errorf("unexpected worker %d", worker)
+		}
+		if names[i] == "c" {
+			return "", errors.New("not found")
+		}
+		return "deleted", nil
+	})
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", peak)
+	}
+	for i, result := range results {
+		if result.Name != names[i] {
+			t.Errorf("expected result %d for %s, got %s", i, names[i], result.Name)
+		}
+	}
+	if results[2].Status != Failed || results[2].Error != "not found" || Failures(results) != 1 {
+		t.Errorf("unexpected failure result %+v", results[2])
+	}
+	if got := Summary(results); got != "4 deleted, 1 failed" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != len(names) {
+		t.Errorf("expected a progress line per item, got %q", out.String())
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Package cache Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultTTL - how long cached lookups are valid for
+const DefaultTTL = 10 * time.Minute
+
+// entry - a cached value and its expiry time
+type entry struct {
+	Value   json.RawMessage `json:"value"`
+	Expires time.Time       `json:"expires"`
+}
+
+// targetCache - the cached values for a single target
+type targetCache struct {
+	Server  string           `json:"server"`
+	Entries map[string]entry `json:"entries"`
+}
+
+var (
+	mutex       sync.Mutex
+	loaded      = map[string]*targetCache{}
+	unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+)
+
+// Dir returns the directory that holds the cache files
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vra-cli"), nil
+}
+
+// file returns the cache file for a target
+func file(target string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, unsafeChars.ReplaceAllString(target, "_")+".json"), nil
+}
+
+// enabled returns true if caching is configured for the API client
+func enabled(APIClient *types.APIClientOptions) bool {
+	return !APIClient.NoCache && APIClient.Config != nil && APIClient.Config.Name != ""
+}
+
+// load returns the cache for the current target, reading it from disk on first use.
+// The caller must hold the mutex.
+func load(APIClient *types.APIClientOptions) *targetCache {
+	target := APIClient.Config.Name
+	if c, ok := loaded[target]; ok {
+		return c
+	}
+	c := &targetCache{Server: APIClient.Config.Server, Entries: map[string]entry{}}
+	loaded[target] = c
+
+	path, err := file(target)
+	if err != nil {
+		return c
+	}
+	cacheBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var onDisk targetCache
+	if err := json.Unmarshal(cacheBytes, &onDisk); err != nil {
+		log.Debugln("Cache: ignoring unreadable cache file", path, err)
+		return c
+	}
+	// Discard the cache if the target now points at a different server
+	if onDisk.Server == c.Server && onDisk.Entries != nil {
+		c.Entries = onDisk.Entries
+	}
+	return c
+}
+
+// save writes the cache for the current target to disk. The caller must hold the mutex.
+func save(APIClient *types.APIClientOptions, c *targetCache) {
+	path, err := file(APIClient.Config.Name)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for key, e := range c.Entries {
+		if now.After(e.Expires) {
+			delete(c.Entries, key)
+		}
+	}
+	cacheBytes, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Debugln("Cache: unable to create cache directory", err)
+		return
+	}
+	if err := ioutil.WriteFile(path, cacheBytes, 0600); err != nil {
+		log.Debugln("Cache: unable to write cache file", err)
+	}
+}
+
+// Get reads a cached value into v, returning false if it is missing or expired
+func Get(APIClient *types.APIClientOptions, key string, v interface{}) bool {
+	if !enabled(APIClient) {
+		return false
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	e, ok := load(APIClient).Entries[key]
+	if !ok || time.Now().After(e.Expires) {
+		return false
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return false
+	}
+	log.Debugln("Cache: hit", key)
+	return true
+}
+
+// Set caches v under key for the configured TTL
+func Set(APIClient *types.APIClientOptions, key string, v interface{}) {
+	if !enabled(APIClient) {
+		return
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	ttl := APIClient.CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	c := load(APIClient)
+	c.Entries[key] = entry{Value: value, Expires: time.Now().Add(ttl)}
+	save(APIClient, c)
+}
+
+// Invalidate removes all cached values whose key starts with prefix
+func Invalidate(APIClient *types.APIClientOptions, prefix string) {
+	if !enabled(APIClient) {
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	c := load(APIClient)
+	for key := range c.Entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.Entries, key)
+		}
+	}
+	save(APIClient, c)
+}
+
+// Clear deletes the cache for a target, or for all targets if target is empty
+func Clear(target string) ([]string, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	var removed []string
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	if target != "" {
+		path, _ := file(target)
+		paths = append(paths, path)
+		delete(loaded, target)
+	} else {
+		paths, _ = filepath.Glob(filepath.Join(dir, "*.json"))
+		loaded = map[string]*targetCache{}
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
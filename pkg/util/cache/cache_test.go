@@ -0,0 +1,119 @@
+/*
+Package cache Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+)
+
+// testClient returns an API client for a target, with the cache in a temporary directory
+func testClient(t *testing.T, target, server string) *types.APIClientOptions {
+	t.Helper()
+	return &types.APIClientOptions{Config: &types.Config{Name: target, Server: server}, CacheTTL: time.Minute}
+}
+
+func setup(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"XDG_CACHE_HOME", "HOME", "LocalAppData"} {
+		old, ok := os.LookupEnv(env)
+		os.Setenv(env, dir)
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(env, old)
+			} else {
+				os.Unsetenv(env)
+			}
+		})
+	}
+	loaded = map[string]*targetCache{}
+	t.Cleanup(func() { loaded = map[string]*targetCache{} })
+}
+
+func TestGetSet(t *testing.T) {
+	setup(t)
+	client := testClient(t, "lab", "vra.lab.local")
+	Set(client, "project/Field Demo", "1234")
+
+	var id string
+	if !Get(client, "project/Field Demo", &id) || id != "1234" {
+		t.Errorf("Get() = %q, want 1234", id)
+	}
+	if Get(client, "project/Other", &id) {
+		t.Error("Get() of a missing key should miss")
+	}
+	client.NoCache = true
+	if Get(client, "project/Field Demo", &id) {
+		t.Error("Get() with --no-cache should miss")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	setup(t)
+	client := testClient(t, "lab", "vra.lab.local")
+	client.CacheTTL = time.Millisecond
+	Set(client, "project/Field Demo", "1234")
+	time.Sleep(5 * time.Millisecond)
+
+	var id string
+	if Get(client, "project/Field Demo", &id) {
+		t.Error("Get() of an expired value should miss")
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	setup(t)
+	client := testClient(t, "lab", "vra.lab.local")
+	Set(client, "endpoint/Field Demo/git", "1")
+	Set(client, "endpoint/Field Demo/docker", "2")
+	Set(client, "project/Field Demo", "3")
+	Invalidate(client, "endpoint/")
+
+	var id string
+	if Get(client, "endpoint/Field Demo/git", &id) || Get(client, "endpoint/Field Demo/docker", &id) {
+		t.Error("Get() of an invalidated value should miss")
+	}
+	if !Get(client, "project/Field Demo", &id) {
+		t.Error("Invalidate() removed a value that does not match the prefix")
+	}
+}
+
+func TestServerChange(t *testing.T) {
+	setup(t)
+	Set(testClient(t, "lab", "vra.lab.local"), "project/Field Demo", "1234")
+
+	// Read the cache from disk again, with the target now pointing at another server
+	loaded = map[string]*targetCache{}
+	var id string
+	if !Get(testClient(t, "lab", "vra.lab.local"), "project/Field Demo", &id) {
+		t.Fatal("Get() should read the cache written for the same server")
+	}
+	loaded = map[string]*targetCache{}
+	if Get(testClient(t, "lab", "vra.prod.local"), "project/Field Demo", &id) {
+		t.Error("Get() should discard a cache written for another server")
+	}
+}
+
+func TestClear(t *testing.T) {
+	setup(t)
+	Set(testClient(t, "lab", "vra.lab.local"), "project/Field Demo", "1")
+	Set(testClient(t, "prod", "vra.prod.local"), "project/Field Demo", "2")
+
+	removed, err := Clear("lab")
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("Clear(lab) = %v, %v, want 1 file removed", removed, err)
+	}
+	var id string
+	if Get(testClient(t, "lab", "vra.lab.local"), "project/Field Demo", &id) {
+		t.Error("Get() after Clear() should miss")
+	}
+	if removed, err := Clear(""); err != nil || len(removed) != 1 {
+		t.Errorf("Clear() = %v, %v, want the other target's file removed", removed, err)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Package canonical Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package canonical
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ServerFields - top-level fields generated by the server, which are removed from canonical exports.
+// Fields starting with an underscore (e.g. _link) are removed at any depth.
+var ServerFields = []string{"id", "orgId", "tenantId", "createdAt", "createdBy", "updatedAt", "updatedBy", "createdMillis", "updatedMillis"}
+
+// Normalise converts a decoded YAML or JSON object into plain maps, slices and scalars, removing
+// the server generated fields, any additional top-level fields in strip, and null values
+func Normalise(v interface{}, strip ...string) interface{} {
+	v = normalise(v)
+	if m, ok := v.(map[string]interface{}); ok {
+		for _, field := range append(ServerFields, strip...) {
+			delete(m, field)
+		}
+	}
+	return v
+}
+
+func normalise(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[fmt.Sprint(k)] = item
+		}
+		return normalise(m)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			if item == nil || strings.HasPrefix(k, "_") {
+				continue
+			}
+			m[k] = normalise(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(value))
+		for i, item := range value {
+			s[i] = normalise(item)
+		}
+		return s
+	}
+	return v
+}
+
+// YAML returns the canonical form of a single or multi-document YAML file, with sorted keys
+func YAML(content []byte, strip ...string) ([]byte, error) {
+	var out bytes.Buffer
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var v interface{}
+		if err := decoder.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		document, err := yaml.Marshal(Normalise(v, strip...))
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(document)
+	}
+	return out.Bytes(), nil
+}
+
+// JSON returns the canonical form of an object as indented JSON with sorted keys, using its JSON field tags
+func JSON(object interface{}, strip ...string) ([]byte, error) {
+	jsonBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(jsonBytes, &v); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(Normalise(v, strip...), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// FileName returns a file name for an object that is the same on every platform: characters that are
+// not allowed in Windows, macOS or Linux file names are replaced with _, as are leading and trailing dots
+// and spaces
+func FileName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < 32 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			b.WriteRune('_')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	safe := b.String()
+	trimmed := strings.Trim(safe, ". ")
+	if trimmed == "" {
+		return "_"
+	}
+	if trimmed != safe {
+		start := strings.Index(safe, trimmed)
+		safe = strings.Repeat("_", start) + trimmed + strings.Repeat("_", len(safe)-start-len(trimmed))
+	}
+	return safe
+}
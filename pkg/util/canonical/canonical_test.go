@@ -0,0 +1,79 @@
+/*
+Package canonical Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package canonical
+
+import (
+	"testing"
+)
+
+func TestYAML(t *testing.T) {
+	content := []byte(`---
+project: Demo
+kind: PIPELINE
+id: 8f3c
+name: Build
+_link: /codestream/api/pipelines/8f3c
+updatedAt: "2021-10-01"
+stages:
+  Build:
+    tasks:
+      Compile:
+        type: CI
+        id: task-id
+        _updateTimeInMicros: 12
+        input:
+          steps: null
+`)
+	expected := `---
+kind: PIPELINE
+name: Build
+project: Demo
+stages:
+  Build:
+    tasks:
+      Compile:
+        id: task-id
+        input: {}
+        type: CI
+`
+	out, err := YAML(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("unexpected canonical YAML:\n%s", out)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	object := struct {
+		Name    string `json:"name"`
+		ID      string `json:"id"`
+		Version string `json:"version"`
+		Link    string `json:"_link"`
+		Yaml    string `json:"yaml"`
+	}{"Notify", "1", "1.0", "/link", "runtime: nodejs"}
+	out, err := JSON(object, "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "{\n  \"name\": \"Notify\",\n  \"yaml\": \"runtime: nodejs\"\n}\n" {
+		t.Errorf("unexpected canonical JSON:\n%s", out)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Build & Deploy": "Build & Deploy",
+		"a/b:c":          "a_b_c",
+		"..":             "_",
+		" draft.":        "_draft_",
+		"What?":          "What_",
+	} {
+		if got := FileName(name); got != expected {
+			t.Errorf("FileName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
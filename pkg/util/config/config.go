@@ -5,6 +5,7 @@ SPDX-License-Identifier: BSD-2-Clause
 package config
 
 import (
+	"errors"
 	"os"
 
 	"github.com/mitchellh/go-homedir"
@@ -59,27 +60,32 @@ func GetConfigFromFile(configFile string) *types.Config {
 		log.Debugln("Using config:", viper.ConfigFileUsed())
 	}
 
-	config := types.Config{}
-
 	currentTargetName := viper.GetString("currentTargetName")
-	if currentTargetName != "" {
-		log.Debugln("Context:", currentTargetName)
-		configuration := viper.Sub("target." + currentTargetName)
-		if configuration == nil { // Sub returns nil if the key cannot be found
-			log.Fatalln("Target configuration not found")
-		}
-		config = types.Config{
-			Name:        currentTargetName,
-			Domain:      configuration.GetString("domain"),
-			Server:      sanitize.URL(configuration.GetString("server")),
-			Username:    configuration.GetString("username"),
-			Password:    configuration.GetString("password"),
-			APIToken:    configuration.GetString("apitoken"),
-			AccessToken: configuration.GetString("accesstoken"),
-		}
-	} else {
+	if currentTargetName == "" {
 		log.Fatalln("No target specified, use `vra-cli config use-target --name <target name>` to specify a name")
 	}
+	log.Debugln("Context:", currentTargetName)
+	config, err := GetTargetConfig(currentTargetName)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	return &config
+	return config
+}
+
+// GetTargetConfig returns the config object for a named target from the loaded configuration file
+func GetTargetConfig(name string) (*types.Config, error) {
+	configuration := viper.Sub("target." + name)
+	if configuration == nil { // Sub returns nil if the key cannot be found
+		return nil, errors.New("Target configuration not found")
+	}
+	return &types.Config{
+		Name:        name,
+		Domain:      configuration.GetString("domain"),
+		Server:      sanitize.URL(configuration.GetString("server")),
+		Username:    configuration.GetString("username"),
+		Password:    configuration.GetString("password"),
+		APIToken:    configuration.GetString("apitoken"),
+		AccessToken: configuration.GetString("accesstoken"),
+	}, nil
 }
@@ -0,0 +1,52 @@
+/*
+Package conflict Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package conflict
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Policies for importing an object that already exists
+const (
+	Skip      = "skip"      // Leave the existing object unchanged
+	Overwrite = "overwrite" // Update the existing object
+	Rename    = "rename"    // Import the object under a new name
+	Fail      = "fail"      // Report the object as failed
+)
+
+// Policies - the valid conflict policies
+var Policies = []string{Skip, Overwrite, Rename, Fail}
+
+// ErrExists is returned when an object already exists and the policy is Fail
+var ErrExists = errors.New("already exists")
+
+// maxRenames - the number of names tried before giving up
+const maxRenames = 100
+
+// Valid returns true if the policy is a valid conflict policy
+func Valid(policy string) bool {
+	for _, p := range Policies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// NewName returns the first of "name (2)", "name (3)" and so on that does not exist
+func NewName(name string, exists func(name string) (bool, error)) (string, error) {
+	for i := 2; i < maxRenames+2; i++ {
+		candidate := name + " (" + strconv.Itoa(i) + ")"
+		found, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return candidate, nil
+		}
+	}
+	return "", errors.New("unable to find a free name for " + name)
+}
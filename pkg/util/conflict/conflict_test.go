@@ -0,0 +1,26 @@
+/*
+Package conflict Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package conflict
+
+import (
+	"testing"
+)
+
+func TestNewName(t *testing.T) {
+	existing := map[string]bool{"Build": true, "Build (2)": true}
+	name, err := NewName("Build", func(name string) (bool, error) { return existing[name], nil })
+	if err != nil || name != "Build (3)" {
+		t.Errorf("expected Build (3), got %q %v", name, err)
+	}
+	if _, err := NewName("Build", func(name string) (bool, error) { return true, nil }); err == nil {
+		t.Error("expected an error when every name is taken")
+	}
+}
+
+func TestValid(t *testing.T) {
+	if !Valid(Rename) || Valid("") || Valid("merge") {
+		t.Error("unexpected policy validation")
+	}
+}
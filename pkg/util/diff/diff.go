@@ -0,0 +1,186 @@
+/*
+Package diff Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+)
+
+// Change - a single difference between two objects
+type Change struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"` // added, removed or changed
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// String returns the change in a +/-/~ format
+func (c Change) String() string {
+	switch c.Type {
+	case "added":
+		return fmt.Sprintf("+ %s: %v", c.Path, c.To)
+	case "removed":
+		return fmt.Sprintf("- %s: %v", c.Path, c.From)
+	}
+	return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.From, c.To)
+}
+
+// Normalise converts a decoded YAML or JSON object into plain maps, slices and
+// scalars, dropping the top-level fields in ignore and any empty values, so that
+// local files and server state can be compared
+func Normalise(v interface{}, ignore ...string) interface{} {
+	v = normalise(v)
+	if m, ok := v.(map[string]interface{}); ok {
+		for _, field := range ignore {
+			delete(m, field)
+		}
+	}
+	return v
+}
+
+// NormaliseYAML decodes and normalises a YAML (or JSON) document
+func NormaliseYAML(content []byte, ignore ...string) (interface{}, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(content, &v); err != nil {
+		return nil, err
+	}
+	return Normalise(v, ignore...), nil
+}
+
+// NormaliseStruct normalises a struct using its JSON field tags
+func NormaliseStruct(s interface{}, ignore ...string) (interface{}, error) {
+	jsonBytes, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(jsonBytes, &v); err != nil {
+		return nil, err
+	}
+	return Normalise(v, ignore...), nil
+}
+
+func normalise(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			if item = normalise(item); !isEmpty(item) {
+				m[fmt.Sprint(k)] = item
+			}
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			if item = normalise(item); !isEmpty(item) {
+				m[k] = item
+			}
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(value))
+		for i, item := range value {
+			s[i] = normalise(item)
+		}
+		return s
+	case int:
+		return float64(value) // Match the numbers decoded from JSON
+	case int64:
+		return float64(value)
+	case string:
+		return strings.TrimRight(value, "\n")
+	}
+	return v
+}
+
+// isEmpty returns true for values that are equivalent to a missing field
+func isEmpty(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// YAML returns the normalised object as YAML with sorted keys
+func YAML(v interface{}) string {
+	yamlBytes, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(yamlBytes)
+}
+
+// Unified returns a unified diff of two normalised objects, or an empty string if they are the same
+func Unified(from, to interface{}, fromName, toName string) string {
+	unified, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(YAML(from)),
+		B:        difflib.SplitLines(YAML(to)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	return unified
+}
+
+// Structured returns the changes between two normalised objects, ordered by path
+func Structured(from, to interface{}) []Change {
+	var changes []Change
+	compare("", from, to, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func compare(path string, from, to interface{}, changes *[]Change) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		for k, v := range fromMap {
+			if other, ok := toMap[k]; ok {
+				compare(join(path, k), v, other, changes)
+			} else {
+				*changes = append(*changes, Change{Path: join(path, k), Type: "removed", From: v})
+			}
+		}
+		for k, v := range toMap {
+			if _, ok := fromMap[k]; !ok {
+				*changes = append(*changes, Change{Path: join(path, k), Type: "added", To: v})
+			}
+		}
+		return
+	}
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice && len(fromSlice) == len(toSlice) {
+		for i := range fromSlice {
+			compare(fmt.Sprintf("%s[%d]", path, i), fromSlice[i], toSlice[i], changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Path: path, Type: "changed", From: from, To: to})
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
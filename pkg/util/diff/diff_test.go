@@ -0,0 +1,30 @@
+/*
+Package diff Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package diff
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestStructured(t *testing.T) {
+	live, err := NormaliseYAML([]byte("name: build\nconcurrency: 10\ndescription: ''\ninput:\n  branch: main\n"))
+	assert.NilError(t, err)
+	local, err := NormaliseStruct(map[string]interface{}{
+		"name":        "build",
+		"concurrency": 20,
+		"input":       map[string]string{"branch": "main", "tag": "v1"},
+		"_link":       "/pipelines/1",
+	}, "_link")
+	assert.NilError(t, err)
+
+	changes := Structured(live, local)
+	assert.Equal(t, len(changes), 2)
+	assert.Equal(t, changes[0].String(), "~ concurrency: 10 -> 20")
+	assert.Equal(t, changes[1].String(), "+ input.tag: v1")
+	assert.Equal(t, len(Structured(live, live)), 0)
+	assert.Assert(t, Unified(live, local, "server", "local") != "")
+}
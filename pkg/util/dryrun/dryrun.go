@@ -0,0 +1,174 @@
+/*
+Package dryrun Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ID is the identifier given to objects that would have been created
+const ID = "dry-run"
+
+// Mutation - an HTTP request that was not sent because of --dry-run
+type Mutation struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+var (
+	enabled   bool
+	mutex     sync.Mutex
+	mutations []Mutation
+)
+
+// readOnlyPaths are POST requests that do not change anything on the server
+var readOnlyPaths = []string{
+	"/iaas/api/login",
+	"/csp/gateway/am/api/login",
+	"/csp/gateway/am/idp/auth/login",
+	"/iaas/api/cloud-accounts-vsphere/region-enumeration",
+	"/vco/api/packages/import-details",
+}
+
+// statusCodes are the success codes the vRA SDK expects, where they differ from the defaults
+var statusCodes = map[string]int{
+	"DELETE /deployment/api/deployments/": http.StatusOK,
+}
+
+// Enable turns dry-run mode on or off, and clears the recorded mutations
+func Enable(on bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	enabled = on
+	mutations = nil
+}
+
+// Enabled returns true when mutating requests are not being sent
+func Enabled() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return enabled
+}
+
+// Mutations returns the requests that were not sent
+func Mutations() []Mutation {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return append([]Mutation(nil), mutations...)
+}
+
+// Transport wraps an http.RoundTripper so that, in dry-run mode, mutating requests
+// are printed instead of sent. Read requests are passed through, so the lookups a
+// command makes before changing anything still run against the server.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Enabled() || !isMutation(req) {
+		return t.base.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	mutation := Mutation{Method: req.Method, Path: req.URL.Path, Query: req.URL.RawQuery, Body: describe(req.Header.Get("Content-Type"), body)}
+	mutex.Lock()
+	mutations = append(mutations, mutation)
+	mutex.Unlock()
+
+	log.Infoln("[dry-run]", mutation.Method, mutation.Path)
+	if mutation.Body != "" {
+		log.Debugln("[dry-run] Request body:", mutation.Body)
+	}
+	return response(req, body), nil
+}
+
+// isMutation returns true for requests that would change objects on the server
+func isMutation(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	for _, path := range readOnlyPaths {
+		if req.URL.Path == path {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns a printable version of the request body
+func describe(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.Contains(contentType, "json") || strings.Contains(contentType, "yaml") || strings.HasPrefix(contentType, "text/") {
+		return string(body)
+	}
+	return "(" + http.DetectContentType(body) + ", " + strconv.Itoa(len(body)) + " bytes)"
+}
+
+// response builds the reply to a request that was not sent. Creates and updates echo the
+// request body, with a placeholder ID, so callers that read the result keep working.
+func response(req *http.Request, body []byte) *http.Response {
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodPost:
+		status = http.StatusCreated
+	case http.MethodDelete:
+		status = http.StatusNoContent
+	}
+	for prefix, code := range statusCodes {
+		if strings.HasPrefix(req.Method+" "+req.URL.Path, prefix) {
+			status = code
+		}
+	}
+
+	var content []byte
+	if status != http.StatusNoContent {
+		object := map[string]interface{}{}
+		if json.Unmarshal(body, &object) != nil || object == nil {
+			object = map[string]interface{}{}
+		}
+		if _, ok := object["id"]; !ok {
+			object["id"] = ID
+		}
+		content, _ = json.Marshal(object)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}, "X-Dry-Run": []string{"true"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(content)),
+		ContentLength: int64(len(content)),
+		Request:       req,
+	}
+}
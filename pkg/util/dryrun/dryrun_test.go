@@ -0,0 +1,53 @@
+/*
+Package dryrun Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package dryrun
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Write([]byte(`{"id":"live"}`))
+	}))
+	defer server.Close()
+
+	Enable(true)
+	defer Enable(false)
+	client := &http.Client{Transport: Transport(nil)}
+
+	if _, err := client.Get(server.URL + "/pipeline/api/pipelines"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Post(server.URL+"/pipeline/api/variables", "application/json", strings.NewReader(`{"name":"test"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&created)
+	if resp.StatusCode != http.StatusCreated || created["id"] != ID || created["name"] != "test" {
+		t.Errorf("unexpected create response %d %v", resp.StatusCode, created)
+	}
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/pipeline/api/variables/1", nil)
+	if resp, err = client.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected delete response %v %v", resp, err)
+	}
+	if _, err := client.Post(server.URL+"/iaas/api/login", "application/json", strings.NewReader(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != 2 {
+		t.Errorf("expected the GET and login to be sent, %d requests were sent", sent)
+	}
+	if mutations := Mutations(); len(mutations) != 2 || mutations[1].Method != http.MethodDelete {
+		t.Errorf("unexpected mutations %v", mutations)
+	}
+}
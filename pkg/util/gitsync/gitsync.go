@@ -0,0 +1,180 @@
+/*
+Package gitsync Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package gitsync
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// StateFile is the file, in the repository's git directory, that records the last synced commit for each target
+const StateFile = "vra-cli-sync.json"
+
+// State - the last synced commit for a target
+type State struct {
+	Commit string    `json:"commit"`
+	Synced time.Time `json:"synced"`
+}
+
+// File - a file removed or changed since the last synced commit, with its previous content
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Changes - the files changed between two commits
+type Changes struct {
+	Full     bool     // The previous commit is unknown, so every file should be synced
+	Changed  []string // Files added or modified, as absolute paths
+	Previous []File   // The previous content of files deleted or modified, as absolute paths
+}
+
+// Repository - a git working tree
+type Repository struct {
+	Root string
+	repo *git.Repository
+}
+
+// Open opens the git working tree that contains path
+func Open(path string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{Root: worktree.Filesystem.Root(), repo: repo}, nil
+}
+
+// Head returns the commit checked out in the working tree
+func (r *Repository) Head() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// Clean returns true if the working tree has no uncommitted changes
+func (r *Repository) Clean() (bool, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	return status.IsClean(), nil
+}
+
+// Changes returns the files changed between the from commit and the to commit. If from
+// is empty, or is no longer in the repository, a full sync is returned.
+func (r *Repository) Changes(from, to string) (*Changes, error) {
+	if from == "" {
+		return &Changes{Full: true}, nil
+	}
+	fromCommit, err := r.repo.CommitObject(plumbing.NewHash(from))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return &Changes{Full: true}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	toCommit, err := r.repo.CommitObject(plumbing.NewHash(to))
+	if err != nil {
+		return nil, err
+	}
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	diff, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{}
+	for _, change := range diff {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		if action != merkletrie.Delete {
+			changes.Changed = append(changes.Changed, r.path(change.To.Name))
+		}
+		if action != merkletrie.Insert {
+			file, err := fromTree.TreeEntryFile(&change.From.TreeEntry)
+			if err != nil {
+				return nil, err
+			}
+			content, err := file.Contents()
+			if err != nil {
+				return nil, err
+			}
+			changes.Previous = append(changes.Previous, File{Path: r.path(change.From.Name), Content: []byte(content)})
+		}
+	}
+	return changes, nil
+}
+
+// ReadState returns the last synced commit for a target, or an empty State if the target has not been synced
+func (r *Repository) ReadState(target string) (State, error) {
+	states, err := r.readStates()
+	return states[target], err
+}
+
+// WriteState records the last synced commit for a target
+func (r *Repository) WriteState(target, commit string) error {
+	states, err := r.readStates()
+	if err != nil {
+		return err
+	}
+	states[target] = State{Commit: commit, Synced: time.Now().UTC()}
+	content, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.stateFile(), content, 0644)
+}
+
+func (r *Repository) readStates() (map[string]State, error) {
+	states := make(map[string]State)
+	content, err := ioutil.ReadFile(r.stateFile())
+	if os.IsNotExist(err) {
+		return states, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return states, json.Unmarshal(content, &states)
+}
+
+// stateFile returns the path of the state file, in the git directory so that it is never committed
+func (r *Repository) stateFile() string {
+	if storage, ok := r.repo.Storer.(*filesystem.Storage); ok {
+		return filepath.Join(storage.Filesystem().Root(), StateFile)
+	}
+	return filepath.Join(r.Root, git.GitDirName, StateFile)
+}
+
+// path returns the absolute path of a file in the repository
+func (r *Repository) path(name string) string {
+	return filepath.Join(r.Root, filepath.FromSlash(name))
+}
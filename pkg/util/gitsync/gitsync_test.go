@@ -0,0 +1,92 @@
+/*
+Package gitsync Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package gitsync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func commit(t *testing.T, worktree *git.Worktree, message string) string {
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		All:    true,
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitsync-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := repo.Worktree()
+
+	ioutil.WriteFile(filepath.Join(dir, "a.yaml"), []byte("kind: Pipeline\nname: a\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "b.yaml"), []byte("kind: Pipeline\nname: b\n"), 0644)
+	first := commit(t, worktree, "first")
+
+	os.Remove(filepath.Join(dir, "a.yaml"))
+	ioutil.WriteFile(filepath.Join(dir, "b.yaml"), []byte("kind: Pipeline\nname: b2\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "c.yaml"), []byte("kind: Pipeline\nname: c\n"), 0644)
+	second := commit(t, worktree, "second")
+
+	r, err := Open(filepath.Join(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head, _ := r.Head(); head != second {
+		t.Errorf("expected head %s, got %s", second, head)
+	}
+	if clean, _ := r.Clean(); !clean {
+		t.Error("expected a clean working tree")
+	}
+
+	changes, err := r.Changes(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes.Full || len(changes.Changed) != 2 || len(changes.Previous) != 2 {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	for _, f := range changes.Previous {
+		if filepath.Base(f.Path) == "b.yaml" && string(f.Content) != "kind: Pipeline\nname: b\n" {
+			t.Errorf("unexpected previous content %q", f.Content)
+		}
+	}
+	if changes, _ := r.Changes("", second); !changes.Full {
+		t.Error("expected a full sync without a previous commit")
+	}
+
+	if state, _ := r.ReadState("dev"); state.Commit != "" {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if err := r.WriteState("dev", second); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := r.ReadState("dev"); state.Commit != second {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if clean, _ := r.Clean(); !clean {
+		t.Error("the state file should not be in the working tree")
+	}
+}
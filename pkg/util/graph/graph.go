@@ -0,0 +1,134 @@
+/*
+Package graph Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node - an object in a dependency graph
+type Node struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Project string `json:"project,omitempty"`
+	Missing bool   `json:"missing,omitempty"` // The object is referenced, but was not found
+}
+
+// ID returns the unique ID of the node
+func (n *Node) ID() string {
+	return n.Kind + "/" + n.Project + "/" + n.Name
+}
+
+// Edge - a dependency of one node on another
+type Edge struct {
+	From *Node
+	To   *Node
+}
+
+// Graph - a directed graph of objects and the objects they depend on
+type Graph struct {
+	Nodes []*Node
+	Edges []Edge
+	nodes map[string]*Node
+	edges map[string]bool
+}
+
+// New returns an empty graph
+func New() *Graph {
+	return &Graph{nodes: make(map[string]*Node), edges: make(map[string]bool)}
+}
+
+// Add returns the node for an object, adding it to the graph if it is new
+func (g *Graph) Add(kind, project, name string) (*Node, bool) {
+	node := &Node{Kind: kind, Project: project, Name: name}
+	if existing, ok := g.nodes[node.ID()]; ok {
+		return existing, false
+	}
+	g.nodes[node.ID()] = node
+	g.Nodes = append(g.Nodes, node)
+	return node, true
+}
+
+// Connect adds a dependency of from on to
+func (g *Graph) Connect(from, to *Node) {
+	key := from.ID() + "\x00" + to.ID()
+	if !g.edges[key] {
+		g.edges[key] = true
+		g.Edges = append(g.Edges, Edge{From: from, To: to})
+	}
+}
+
+// Kind returns the nodes of a kind, in the order they were added
+func (g *Graph) Kind(kind string) []*Node {
+	var nodes []*Node
+	for _, node := range g.Nodes {
+		if node.Kind == kind {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// shapes - the DOT shape of each kind of node
+var shapes = map[string]string{
+	"Pipeline":          "box",
+	"Variable":          "ellipse",
+	"Endpoint":          "component",
+	"CustomIntegration": "hexagon",
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n  rankdir=LR;\n")
+	for _, node := range g.Nodes {
+		shape := shapes[node.Kind]
+		if shape == "" {
+			shape = "box"
+		}
+		style := ""
+		if node.Missing {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s%s];\n", dotQuote(node.ID()), dotQuote(node.Name+"\n"+node.Kind), shape, style)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(edge.From.ID()), dotQuote(edge.To.ID()))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	ids := make(map[*Node]string)
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, node := range g.Nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		label := mermaidQuote(node.Name + " (" + node.Kind + ")")
+		if node.Missing {
+			label = mermaidQuote(node.Name + " (" + node.Kind + ", missing)")
+		}
+		fmt.Fprintf(&b, "  %s[%s]\n", ids[node], label)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[edge.From], ids[edge.To])
+	}
+	return b.String()
+}
+
+// dotQuote quotes a DOT ID, escaping quotes and newlines
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// mermaidQuote quotes a Mermaid label, escaping quotes as entities
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,51 @@
+/*
+Package graph Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package graph
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func testGraph() *Graph {
+	g := New()
+	deploy, _ := g.Add("Pipeline", "Demo", "Deploy")
+	build, _ := g.Add("Pipeline", "Demo", "Build")
+	region, _ := g.Add("Variable", "Demo", `"region"`)
+	g.Connect(deploy, build)
+	g.Connect(deploy, region)
+	g.Connect(build, region)
+	g.Connect(deploy, build) // Duplicate edges are ignored
+	if _, added := g.Add("Pipeline", "Demo", "Build"); added {
+		panic("duplicate node added")
+	}
+	build.Missing = true
+	return g
+}
+
+func TestDOT(t *testing.T) {
+	assert.Equal(t, testGraph().DOT(), `digraph dependencies {
+  rankdir=LR;
+  "Pipeline/Demo/Deploy" [label="Deploy\nPipeline", shape=box];
+  "Pipeline/Demo/Build" [label="Build\nPipeline", shape=box, style=dashed];
+  "Variable/Demo/\"region\"" [label="\"region\"\nVariable", shape=ellipse];
+  "Pipeline/Demo/Deploy" -> "Pipeline/Demo/Build";
+  "Pipeline/Demo/Deploy" -> "Variable/Demo/\"region\"";
+  "Pipeline/Demo/Build" -> "Variable/Demo/\"region\"";
+}
+`)
+}
+
+func TestMermaid(t *testing.T) {
+	assert.Equal(t, testGraph().Mermaid(), `graph LR
+  n0["Deploy (Pipeline)"]
+  n1["Build (Pipeline, missing)"]
+  n2["#quot;region#quot; (Variable)"]
+  n0 --> n1
+  n0 --> n2
+  n1 --> n2
+`)
+}
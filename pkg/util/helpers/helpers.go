@@ -7,6 +7,7 @@ package helpers
 import (
 	"archive/zip"
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -202,6 +203,17 @@ func AskForConfirmation(s string) bool {
 	}
 }
 
+// AskForInput prompts for a value, returning it without surrounding white space
+func AskForInput(s string) string {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Fprint(os.Stderr, s+": ")
+	response, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		log.Fatal(err)
+	}
+	return strings.TrimSpace(response)
+}
+
 // promptUserForInputs
 // func GetCatalogItemInputs(SchemaProperties map[string]cmd.CatalogItemSchemaProperties) map[string]string {
 // 	inputs := make(map[string]string)
@@ -243,7 +255,7 @@ func StringArrayContains(s []string, str string) bool {
 
 // CreateUserArray - Create an array of users from emails
 func CreateUserArray(emails []string) []*models.User {
-	if emails[0] == "" {
+	if len(emails) == 0 || emails[0] == "" {
 		return nil
 	}
 	users := make([]*models.User, 0, len(emails))
@@ -265,3 +277,44 @@ func UserArrayToString(users []*models.User) string {
 	return strings.Join(userList, ",")
 
 }
+
+// SplitArgs - split a command line into arguments, honouring single and double quotes and backslash escapes
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,46 @@
+/*
+Package helpers Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package helpers
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		err  string
+	}{
+		{line: "", want: nil},
+		{line: "   \t ", want: nil},
+		{line: "get pipeline", want: []string{"get", "pipeline"}},
+		{line: "  get \t pipeline  ", want: []string{"get", "pipeline"}},
+		{line: `use project "Field Demo"`, want: []string{"use", "project", "Field Demo"}},
+		{line: `use project 'Field Demo'`, want: []string{"use", "project", "Field Demo"}},
+		{line: `use project Field\ Demo`, want: []string{"use", "project", "Field Demo"}},
+		{line: `get pipeline --name ""`, want: []string{"get", "pipeline", "--name", ""}},
+		{line: `--name "it's"`, want: []string{"--name", "it's"}},
+		{line: `--name 'a\b'`, want: []string{"--name", `a\b`}},
+		{line: `--name "a\"b"`, want: []string{"--name", `a"b`}},
+		{line: `--name=pre"fix suf"fix`, want: []string{"--name=prefix suffix"}},
+		{line: `use project "Field Demo`, err: "unterminated quote or escape"},
+		{line: `use project 'Field`, err: "unterminated quote or escape"},
+		{line: `get pipeline\`, err: "unterminated quote or escape"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := SplitArgs(tt.line)
+			if tt.err != "" {
+				assert.Error(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Package helpers Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package helpers
+
+import (
+	"strings"
+	"sync"
+)
+
+// Errors - aggregates the errors returned by a batch of operations
+type Errors []error
+
+// Error implements the error interface
+func (e Errors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ForEachParallel calls fn for every index in [0, count) using at most parallel
+// workers. Callers should write results into a pre-sized slice by index to keep
+// them in order. Any errors are returned together as Errors.
+func ForEachParallel(count int, parallel int, fn func(i int) error) error {
+	return ForEachWorker(count, parallel, func(worker, i int) error { return fn(i) })
+}
+
+// ForEachWorker is ForEachParallel, also giving fn the index of the worker calling it,
+// so that callers can keep a client per worker.
+func ForEachWorker(count int, parallel int, fn func(worker, i int) error) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > count {
+		parallel = count
+	}
+
+	errs := make([]error, count)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fn(worker, i)
+			}
+		}(w)
+	}
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var failed Errors
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
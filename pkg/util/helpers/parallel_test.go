@@ -0,0 +1,42 @@
+/*
+Package helpers Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package helpers
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestForEachParallel(t *testing.T) {
+	results := make([]string, 50)
+	err := ForEachParallel(len(results), 4, func(i int) error {
+		results[i] = strconv.Itoa(i)
+		if i%10 == 0 {
+			return errors.New("failed " + strconv.Itoa(i))
+		}
+		return nil
+	})
+	for i, result := range results {
+		assert.Equal(t, result, strconv.Itoa(i)) // Results should stay in order
+	}
+	assert.Equal(t, len(err.(Errors)), 5) // Every error should be returned
+	assert.Error(t, err, "failed 0; failed 10; failed 20; failed 30; failed 40")
+}
+
+func TestForEachWorker(t *testing.T) {
+	workers := make([]int, 50)
+	err := ForEachWorker(len(workers), 4, func(worker, i int) error {
+		workers[i] = worker
+		return nil
+	})
+	assert.NilError(t, err)
+	for _, worker := range workers {
+		assert.Assert(t, worker >= 0 && worker < 4) // Each worker should have its own index
+	}
+	assert.NilError(t, ForEachWorker(0, 4, func(worker, i int) error { return errors.New("called") }))
+}
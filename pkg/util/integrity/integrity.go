@@ -0,0 +1,426 @@
+/*
+Package integrity Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package integrity
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SumsFile is the checksum manifest written alongside exported files
+const SumsFile = "SHA256SUMS"
+
+// SignatureFile is the detached signature of the checksum manifest
+const SignatureFile = SumsFile + ".sig"
+
+// Import policies
+const (
+	PolicyNone     = "none"     // Import anything
+	PolicyChecksum = "checksum" // Refuse files that are not in a checksum manifest, or do not match it
+	PolicySigned   = "signed"   // Also require the checksum manifest to be signed by a trusted key
+)
+
+// Checksum results
+const (
+	StatusOK        = "ok"
+	StatusModified  = "modified"
+	StatusMissing   = "missing"
+	StatusUntracked = "untracked"
+)
+
+// mutex serialises updates to the checksum manifests, as exports can run in parallel
+var mutex sync.Mutex
+
+// Key - a public key trusted for verifying signatures
+type Key struct {
+	Name string
+	ID   string
+	Key  ed25519.PublicKey
+}
+
+// Signature - the result of checking a checksum manifest's signature
+type Signature struct {
+	Signed bool   `json:"signed"`
+	Valid  bool   `json:"valid"`
+	KeyID  string `json:"keyId,omitempty"`
+	Key    string `json:"key,omitempty"` // Name of the trusted key that made the signature
+	Error  string `json:"error,omitempty"`
+}
+
+// FileResult - the result of checking a file against its checksum
+type FileResult struct {
+	File   string `json:"file"`
+	Status string `json:"status"`
+}
+
+// ValidPolicy returns true for a known import policy
+func ValidPolicy(policy string) bool {
+	return policy == "" || policy == PolicyNone || policy == PolicyChecksum || policy == PolicySigned
+}
+
+// KeyDir returns the directory keys are stored in
+func KeyDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vra-cli", "keys"), nil
+}
+
+// keyName - the characters allowed in key names, which are used as file names in the key directory
+var keyName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// validateName returns an error if a key name could refer to a file outside the key directory
+func validateName(name string) error {
+	if !keyName.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("%q is not a valid key name - use letters, numbers, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// KeyID returns the short identifier of a public key
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateKey creates a new signing key pair, returning the path of the public key
+func GenerateKey(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+	dir, err := KeyDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	privatePath := filepath.Join(dir, name+".key")
+	if _, err := os.Stat(privatePath); err == nil {
+		return "", errors.New("a key named " + name + " already exists")
+	}
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(privatePath, []byte(base64.StdEncoding.EncodeToString(private)+"\n"), 0600); err != nil {
+		return "", err
+	}
+	publicPath := filepath.Join(dir, name+".pub")
+	return publicPath, ioutil.WriteFile(publicPath, []byte(formatPublicKey(name, public)), 0644)
+}
+
+// ImportPublicKey trusts a public key, written by GenerateKey, for verifying signatures
+func ImportPublicKey(path, name string) (*Key, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePublicKey(content)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		key.Name = name
+	}
+	if err := validateName(key.Name); err != nil {
+		return nil, err
+	}
+	dir, err := KeyDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	target := filepath.Join(dir, key.Name+".pub")
+	if _, err := os.Stat(target); err == nil {
+		return nil, errors.New("a key named " + key.Name + " already exists")
+	}
+	return key, ioutil.WriteFile(target, []byte(formatPublicKey(key.Name, key.Key)), 0644)
+}
+
+// PublicKeys returns the trusted public keys
+func PublicKeys() ([]*Key, error) {
+	dir, err := KeyDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+	var keys []*Key
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parsePublicKey(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// PublicKey returns the public key file content for a key
+func PublicKey(name string) ([]byte, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	dir, err := KeyDir()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filepath.Join(dir, name+".pub"))
+}
+
+// privateKey loads a signing key
+func privateKey(name string) (ed25519.PrivateKey, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	dir, err := KeyDir()
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, name+".key"))
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("the signing key " + name + " is not valid")
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// formatPublicKey returns the public key file content
+func formatPublicKey(name string, key ed25519.PublicKey) string {
+	return "ed25519 " + base64.StdEncoding.EncodeToString(key) + " " + name + "\n"
+}
+
+// parsePublicKey parses the public key file content
+func parsePublicKey(content []byte) (*Key, error) {
+	fields := strings.Fields(string(content))
+	if len(fields) != 3 || fields[0] != "ed25519" {
+		return nil, errors.New("not an ed25519 public key")
+	}
+	key, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("not an ed25519 public key")
+	}
+	return &Key{Name: fields[2], ID: KeyID(key), Key: key}, nil
+}
+
+// Sum returns the SHA-256 checksum of a file
+func Sum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadSums reads the checksum manifest in a directory, mapping file names to checksums
+func ReadSums(dir string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, SumsFile))
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(parts) == 2 {
+			sums[parts[1]] = parts[0]
+		}
+	}
+	return sums, scanner.Err()
+}
+
+// Record adds a file to the checksum manifest in its directory, and signs the manifest
+// with the named key if one is given
+func Record(file, signingKey string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	sum, err := Sum(file)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(file)
+	sums, err := ReadSums(dir)
+	if os.IsNotExist(err) {
+		sums = make(map[string]string)
+	} else if err != nil {
+		return err
+	}
+	sums[filepath.Base(file)] = sum
+
+	var names []string
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var content bytes.Buffer
+	for _, name := range names {
+		content.WriteString(sums[name] + "  " + name + "\n")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, SumsFile), content.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	signaturePath := filepath.Join(dir, SignatureFile)
+	if signingKey == "" {
+		// The previous signature no longer matches
+		if err := os.Remove(signaturePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	key, err := privateKey(signingKey)
+	if err != nil {
+		return err
+	}
+	public := key.Public().(ed25519.PublicKey)
+	signature := ed25519.Sign(key, content.Bytes())
+	return ioutil.WriteFile(signaturePath, []byte("ed25519 "+KeyID(public)+" "+base64.StdEncoding.EncodeToString(signature)+"\n"), 0644)
+}
+
+// VerifySignature checks the signature of the checksum manifest in a directory against the trusted keys
+func VerifySignature(dir string) *Signature {
+	result := &Signature{}
+	content, err := ioutil.ReadFile(filepath.Join(dir, SignatureFile))
+	if os.IsNotExist(err) {
+		return result
+	} else if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Signed = true
+	fields := strings.Fields(string(content))
+	if len(fields) != 3 || fields[0] != "ed25519" {
+		result.Error = "the signature is not valid"
+		return result
+	}
+	result.KeyID = fields[1]
+	signature, err := base64.StdEncoding.DecodeString(fields[2])
+	if err != nil {
+		result.Error = "the signature is not valid"
+		return result
+	}
+	sums, err := ioutil.ReadFile(filepath.Join(dir, SumsFile))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	keys, err := PublicKeys()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for _, key := range keys {
+		if key.ID == result.KeyID {
+			result.Key = key.Name
+			if ed25519.Verify(key.Key, sums, signature) {
+				result.Valid = true
+			} else {
+				result.Error = "the checksums do not match the signature"
+			}
+			return result
+		}
+	}
+	result.Error = "signed by an untrusted key " + result.KeyID
+	return result
+}
+
+// VerifyDir checks every file in a directory against its checksum manifest
+func VerifyDir(dir string) ([]FileResult, error) {
+	sums, err := ReadSums(dir)
+	if err != nil {
+		return nil, err
+	}
+	var results []FileResult
+	for name, expected := range sums {
+		result := FileResult{File: filepath.Join(dir, name), Status: StatusOK}
+		if sum, err := Sum(result.File); os.IsNotExist(err) {
+			result.Status = StatusMissing
+		} else if err != nil {
+			return nil, err
+		} else if sum != expected {
+			result.Status = StatusModified
+		}
+		results = append(results, result)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, ok := sums[entry.Name()]; !ok && !entry.IsDir() && entry.Name() != SumsFile && entry.Name() != SignatureFile {
+			results = append(results, FileResult{File: filepath.Join(dir, entry.Name()), Status: StatusUntracked})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].File < results[j].File })
+	return results, nil
+}
+
+// Check enforces an import policy for a file, returning an error if it should not be imported
+func Check(policy, file string) error {
+	if policy == "" || policy == PolicyNone {
+		return nil
+	}
+	dir, name := filepath.Split(file)
+	sums, err := ReadSums(dir)
+	if os.IsNotExist(err) {
+		return errors.New(file + " has no " + SumsFile + " checksum manifest")
+	} else if err != nil {
+		return err
+	}
+	expected, ok := sums[name]
+	if !ok {
+		return errors.New(file + " is not in the " + SumsFile + " checksum manifest")
+	}
+	sum, err := Sum(file)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return errors.New(file + " has been modified since it was exported")
+	}
+	if policy == PolicySigned {
+		signature := VerifySignature(dir)
+		if !signature.Signed {
+			return errors.New(file + " is not signed")
+		}
+		if !signature.Valid {
+			return errors.New(file + ": " + signature.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Package integrity Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package integrity
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "integrity-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	os.Setenv("HOME", filepath.Join(dir, "home"))
+
+	exports := filepath.Join(dir, "exports")
+	os.MkdirAll(exports, 0755)
+	file := filepath.Join(exports, "pipeline.yaml")
+	ioutil.WriteFile(file, []byte("name: build\n"), 0644)
+
+	if err := Check(PolicyChecksum, file); err == nil {
+		t.Error("expected a file without a checksum to be refused")
+	}
+	if err := Record(file, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(PolicyChecksum, file); err != nil {
+		t.Error(err)
+	}
+	if err := Check(PolicySigned, file); err == nil {
+		t.Error("expected an unsigned file to be refused")
+	}
+
+	if _, err := GenerateKey("test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Record(file, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(PolicySigned, file); err != nil {
+		t.Error(err)
+	}
+
+	ioutil.WriteFile(file, []byte("name: tampered\n"), 0644)
+	if err := Check(PolicyChecksum, file); err == nil {
+		t.Error("expected a modified file to be refused")
+	}
+	results, err := VerifyDir(exports)
+	if err != nil || len(results) != 1 || results[0].Status != StatusModified {
+		t.Errorf("unexpected results %v %v", results, err)
+	}
+
+	// A checksum manifest edited to match the tampered file no longer matches the signature
+	sum, _ := Sum(file)
+	ioutil.WriteFile(filepath.Join(exports, SumsFile), []byte(sum+"  pipeline.yaml\n"), 0644)
+	if err := Check(PolicyChecksum, file); err != nil {
+		t.Error(err)
+	}
+	if signature := VerifySignature(exports); !signature.Signed || signature.Valid {
+		t.Errorf("expected the signature to be invalid, got %+v", signature)
+	}
+}
+
+func TestKeyNames(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+
+	for _, name := range []string{"", "..", "../escape", "a/b", `a\b`, "a..b", "/tmp/key", "with space"} {
+		if _, err := GenerateKey(name); err == nil {
+			t.Errorf("expected key name %q to be refused by GenerateKey", name)
+		}
+		if _, err := PublicKey(name); err == nil {
+			t.Errorf("expected key name %q to be refused by PublicKey", name)
+		}
+		if _, err := privateKey(name); err == nil {
+			t.Errorf("expected key name %q to be refused by privateKey", name)
+		}
+	}
+	if _, err := GenerateKey("release-2021_v1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The name in a public key file is not trusted
+	public, err := PublicKey("release-2021_v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(string(public))
+	file := filepath.Join(dir, "evil.pub")
+	ioutil.WriteFile(file, []byte(fields[0]+" "+fields[1]+" ../../../escape\n"), 0644)
+	if _, err := ImportPublicKey(file, ""); err == nil {
+		t.Error("expected a public key named ../../../escape to be refused")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.pub")); err == nil {
+		t.Error("public key was written outside the key directory")
+	}
+	if _, err := ImportPublicKey(file, "../escape"); err == nil {
+		t.Error("expected the name ../escape to be refused")
+	}
+	if key, err := ImportPublicKey(file, "imported"); err != nil || key.Name != "imported" {
+		t.Errorf("unexpected key %v %v", key, err)
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Package lint Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// Finding levels
+const (
+	Error   = "error"
+	Warning = "warning"
+)
+
+// Rule IDs
+const (
+	UndefinedVariable   = "undefined-variable"
+	UndefinedInput      = "undefined-input"
+	UnknownReference    = "unknown-reference"
+	UnknownEndpoint     = "unknown-endpoint"
+	EmptyStage          = "empty-stage"
+	DuplicateTask       = "duplicate-task"
+	UnsupportedTaskType = "unsupported-task-type"
+	SchemaViolation     = "schema"
+)
+
+// Rule - a check made by the linter
+type Rule struct {
+	ID          string
+	Level       string
+	Description string
+}
+
+// Rules - the checks made by the linter
+var Rules = []Rule{
+	{UndefinedVariable, Error, "A ${var.name} expression references a Variable that does not exist in the Pipeline's Project"},
+	{UndefinedInput, Error, "An ${input.name} expression references an input the Pipeline does not have"},
+	{UnknownReference, Error, "A stage order, task order or ${Stage.Task} expression references a stage or task that does not exist"},
+	{UnknownEndpoint, Error, "The workspace or a task uses an Endpoint that does not exist in the Pipeline's Project"},
+	{EmptyStage, Warning, "A stage has no tasks"},
+	{DuplicateTask, Error, "A stage has two tasks with the same name, or lists a task more than once in its task order"},
+	{UnsupportedTaskType, Warning, "A task has a type that Code Stream does not support"},
+	{SchemaViolation, Error, "A value does not match the Pipeline schema (see vra-cli schema pipeline)"},
+}
+
+// pipelineSchema - the schema Pipelines are validated against
+var pipelineSchema, _ = schema.For("pipeline")
+
+// namespaces - the first part of expressions that do not reference a stage
+var namespaces = map[string]bool{"var": true, "input": true, "output": true, "pipeline": true}
+
+// expression matches a ${...} expression
+var expression = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Finding - a problem found in a file
+type Finding struct {
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// String formats the finding as file:line:column: level: message [rule]
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", f.File, f.Line, f.Column, f.Level, f.Message, f.Rule)
+}
+
+// Options - the objects Pipelines can reference, keyed by Key(project, name). Nil sets are not checked.
+type Options struct {
+	Variables map[string]bool
+	Endpoints map[string]bool
+}
+
+// Key returns the key of an object in a Project
+func Key(project, name string) string {
+	return project + "/" + name
+}
+
+// Pipeline checks the Code Stream Pipeline exports in the content of a file, returning the findings
+// in the order they appear. Documents that are not Pipelines are ignored.
+func Pipeline(file string, content []byte, options Options) ([]Finding, error) {
+	var findings []Finding
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return findings, nil
+			}
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := document.Content[0]
+		if kind := value(root, "kind"); kind == nil || !strings.EqualFold(kind.Value, "PIPELINE") {
+			continue
+		}
+		l := &linter{file: file, options: options}
+		l.pipeline(root)
+		l.schema(root)
+		sort.SliceStable(l.findings, func(i, j int) bool {
+			a, b := l.findings[i], l.findings[j]
+			return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+		})
+		findings = append(findings, l.findings...)
+	}
+}
+
+// linter - the state of checking a Pipeline
+type linter struct {
+	file     string
+	options  Options
+	project  string
+	inputs   map[string]bool
+	stages   map[string]map[string]bool // Task names by stage name
+	findings []Finding
+}
+
+func (l *linter) add(rule string, node *yaml.Node, format string, a ...interface{}) {
+	finding := Finding{Rule: rule, Message: fmt.Sprintf(format, a...), File: l.file, Line: node.Line, Column: node.Column}
+	for _, r := range Rules {
+		if r.ID == rule {
+			finding.Level = r.Level
+		}
+	}
+	l.findings = append(l.findings, finding)
+}
+
+func (l *linter) pipeline(root *yaml.Node) {
+	if project := value(root, "project"); project != nil {
+		l.project = project.Value
+	}
+	l.inputs = make(map[string]bool)
+	if input := value(root, "input"); input != nil && input.Kind == yaml.MappingNode {
+		for i := 0; i < len(input.Content); i += 2 {
+			l.inputs[input.Content[i].Value] = true
+		}
+	}
+
+	// Stages and their tasks
+	l.stages = make(map[string]map[string]bool)
+	stages := value(root, "stages")
+	if stages != nil && stages.Kind == yaml.MappingNode {
+		for i := 0; i < len(stages.Content); i += 2 {
+			l.stage(stages.Content[i], stages.Content[i+1])
+		}
+	}
+	if order := value(root, "stageOrder"); order != nil && order.Kind == yaml.SequenceNode {
+		for _, stage := range order.Content {
+			if _, ok := l.stages[stage.Value]; !ok {
+				l.add(UnknownReference, stage, "stage order references stage %q, which does not exist", stage.Value)
+			}
+		}
+	}
+
+	// Endpoints
+	if workspace := value(root, "workspace"); workspace != nil {
+		if endpoint := value(workspace, "endpoint"); endpoint != nil {
+			l.endpoint(endpoint)
+		}
+	}
+	if stages != nil && stages.Kind == yaml.MappingNode {
+		for i := 1; i < len(stages.Content); i += 2 {
+			tasks := value(stages.Content[i], "tasks")
+			if tasks == nil || tasks.Kind != yaml.MappingNode {
+				continue
+			}
+			for t := 1; t < len(tasks.Content); t += 2 {
+				if endpoints := value(tasks.Content[t], "endpoints"); endpoints != nil && endpoints.Kind == yaml.MappingNode {
+					for e := 1; e < len(endpoints.Content); e += 2 {
+						l.endpoint(endpoints.Content[e])
+					}
+				}
+			}
+		}
+	}
+
+	l.expressions(root)
+}
+
+// schema validates the Pipeline against the schema. Unsupported task types are reported by their own rule.
+func (l *linter) schema(root *yaml.Node) {
+	for _, e := range pipelineSchema.Validate(root) {
+		if e.UnsupportedTaskType() {
+			continue
+		}
+		l.findings = append(l.findings, Finding{Rule: SchemaViolation, Level: Error, Message: e.Error(), File: l.file, Line: e.Line, Column: e.Column})
+	}
+}
+
+func (l *linter) stage(name, stage *yaml.Node) {
+	tasks := make(map[string]bool)
+	l.stages[name.Value] = tasks
+	taskNodes := value(stage, "tasks")
+	if taskNodes == nil || taskNodes.Kind != yaml.MappingNode || len(taskNodes.Content) == 0 {
+		l.add(EmptyStage, name, "stage %q has no tasks", name.Value)
+		return
+	}
+	for i := 0; i < len(taskNodes.Content); i += 2 {
+		taskName, task := taskNodes.Content[i], taskNodes.Content[i+1]
+		if tasks[taskName.Value] {
+			l.add(DuplicateTask, taskName, "stage %q has more than one task named %q", name.Value, taskName.Value)
+		}
+		tasks[taskName.Value] = true
+		if taskType := value(task, "type"); taskType != nil && !supported(taskType.Value) {
+			l.add(UnsupportedTaskType, taskType, "task %q has type %q, which is not supported", taskName.Value, taskType.Value)
+		}
+	}
+	if order := value(stage, "taskOrder"); order != nil && order.Kind == yaml.SequenceNode {
+		ordered := make(map[string]bool)
+		for _, step := range order.Content {
+			for _, task := range strings.Split(step.Value, ",") { // Tasks that run in parallel are comma separated
+				task = strings.TrimSpace(task)
+				if !tasks[task] {
+					l.add(UnknownReference, step, "task order of stage %q references task %q, which does not exist", name.Value, task)
+				} else if ordered[task] {
+					l.add(DuplicateTask, step, "task order of stage %q lists task %q more than once", name.Value, task)
+				}
+				ordered[task] = true
+			}
+		}
+	}
+}
+
+func (l *linter) endpoint(endpoint *yaml.Node) {
+	if l.options.Endpoints == nil || endpoint.Value == "" || strings.Contains(endpoint.Value, "${") {
+		return
+	}
+	if !l.options.Endpoints[Key(l.project, endpoint.Value)] {
+		l.add(UnknownEndpoint, endpoint, "Endpoint %q does not exist in Project %q", endpoint.Value, l.project)
+	}
+}
+
+// expressions checks the ${...} expressions in every value under node
+func (l *linter) expressions(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		for _, match := range expression.FindAllStringSubmatch(node.Value, -1) {
+			l.expression(node, match[1])
+		}
+		return
+	}
+	for _, child := range node.Content {
+		l.expressions(child)
+	}
+}
+
+func (l *linter) expression(node *yaml.Node, expression string) {
+	parts := strings.Split(expression, ".")
+	if len(parts) < 2 {
+		return // A built-in binding, such as ${executionId}
+	}
+	switch parts[0] {
+	case "var":
+		if l.options.Variables != nil && !l.options.Variables[Key(l.project, parts[1])] {
+			l.add(UndefinedVariable, node, "Variable %q does not exist in Project %q", parts[1], l.project)
+		}
+		return
+	case "input":
+		if !l.inputs[parts[1]] {
+			l.add(UndefinedInput, node, "input %q is not defined by the Pipeline", parts[1])
+		}
+		return
+	}
+	tasks, ok := l.stages[parts[0]]
+	if !ok {
+		if !namespaces[parts[0]] {
+			l.add(UnknownReference, node, "expression ${%s} references stage %q, which does not exist", expression, parts[0])
+		}
+		return
+	}
+	if !tasks[parts[1]] {
+		l.add(UnknownReference, node, "expression ${%s} references task %q, which does not exist in stage %q", expression, parts[1], parts[0])
+	}
+}
+
+// value returns the value of a key in a mapping node, or nil
+func value(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func supported(taskType string) bool {
+	for _, t := range schema.TaskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+/*
+Package lint Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package lint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testPipeline = `---
+project: Field Demo
+kind: PIPELINE
+name: Deploy
+concurrency: high
+input:
+  version: ''
+workspace:
+  endpoint: Docker Host
+stageOrder:
+  - Build
+  - Test
+  - Release
+stages:
+  Build:
+    taskOrder:
+      - Compile,Package
+    tasks:
+      Compile:
+        type: SSH
+        endpoints:
+          agent: Build Host
+        input:
+          script: make ${input.version} ${input.branch} REGION=${var.region} KEY=${var.key}
+      Compile:
+        type: SSH
+  Test:
+    tasks: {}
+  Deploy:
+    tasks:
+      Notify:
+        type: Slack
+        input:
+          message: ${Build.Compile.output.exitCode} ${Build.Publish.status} ${Stage.Task.status} ${executionId}
+`
+
+func TestPipeline(t *testing.T) {
+	findings, err := Pipeline("deploy.yaml", []byte(testPipeline), Options{
+		Variables: map[string]bool{Key("Field Demo", "region"): true},
+		Endpoints: map[string]bool{Key("Field Demo", "Docker Host"): true},
+	})
+	assert.NilError(t, err)
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Rule+" "+f.Message)
+	}
+	assert.DeepEqual(t, got, []string{
+		`schema concurrency: expected integer or null, found string`,
+		`unknown-reference stage order references stage "Release", which does not exist`,
+		`unknown-reference task order of stage "Build" references task "Package", which does not exist`,
+		`unknown-endpoint Endpoint "Build Host" does not exist in Project "Field Demo"`,
+		`undefined-input input "branch" is not defined by the Pipeline`,
+		`undefined-variable Variable "key" does not exist in Project "Field Demo"`,
+		`duplicate-task stage "Build" has more than one task named "Compile"`,
+		`empty-stage stage "Test" has no tasks`,
+		`unsupported-task-type task "Notify" has type "Slack", which is not supported`,
+		`unknown-reference expression ${Build.Publish.status} references task "Publish", which does not exist in stage "Build"`,
+		`unknown-reference expression ${Stage.Task.status} references stage "Stage", which does not exist`,
+	})
+	assert.Equal(t, findings[6].Line, 25)
+	assert.Equal(t, findings[7].Level, Warning)
+}
+
+func TestPipelineUnchecked(t *testing.T) {
+	// Variables and Endpoints are only checked when they are known, and other kinds are ignored
+	findings, err := Pipeline("deploy.yaml", []byte(testPipeline+"---\nkind: ENDPOINT\nname: ${var.missing}\n"), Options{})
+	assert.NilError(t, err)
+	for _, f := range findings {
+		assert.Assert(t, f.Rule != UndefinedVariable && f.Rule != UnknownEndpoint, f.String())
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	out, err := SARIF([]Finding{{Rule: EmptyStage, Level: Warning, Message: "empty", File: "pipelines/deploy.yaml", Line: 3, Column: 2}}, "1.0.0")
+	assert.NilError(t, err)
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID    string
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	assert.NilError(t, json.Unmarshal(out, &log))
+	assert.Equal(t, log.Version, "2.1.0")
+	assert.Equal(t, log.Runs[0].Results[0].RuleID, EmptyStage)
+	assert.Equal(t, log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region.StartLine, 3)
+}
+
+func TestSARIFLocations(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NilError(t, err)
+	outside := filepath.Join(filepath.Dir(cwd), "other", "deploy.yaml")
+	findings := []Finding{
+		{Rule: EmptyStage, Level: Warning, File: filepath.Join("pipelines", "deploy.yaml")},
+		{Rule: EmptyStage, Level: Warning, File: filepath.Join(cwd, "pipelines", "my pipeline.yaml")},
+		{Rule: EmptyStage, Level: Warning, File: outside},
+	}
+	out, err := SARIF(findings, "1.0.0")
+	assert.NilError(t, err)
+	var log struct {
+		Runs []struct {
+			OriginalURIBaseIDs map[string]struct{ URI string } `json:"originalUriBaseIds"`
+			Results            []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string
+							URIBaseID string `json:"uriBaseId"`
+						}
+					}
+				}
+			}
+		}
+	}
+	assert.NilError(t, json.Unmarshal(out, &log))
+	run := log.Runs[0]
+	assert.Equal(t, run.OriginalURIBaseIDs["%SRCROOT%"].URI, fileURI(cwd)+"/")
+	for i, want := range []struct{ uri, base string }{
+		{uri: "pipelines/deploy.yaml", base: "%SRCROOT%"},
+		{uri: "pipelines/my%20pipeline.yaml", base: "%SRCROOT%"},
+		{uri: fileURI(outside), base: ""},
+	} {
+		location := run.Results[i].Locations[0].PhysicalLocation.ArtifactLocation
+		assert.Equal(t, location.URI, want.uri)
+		assert.Equal(t, location.URIBaseID, want.base)
+	}
+	assert.Assert(t, strings.HasPrefix(fileURI(outside), "file:///"))
+}
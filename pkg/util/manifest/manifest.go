@@ -0,0 +1,431 @@
+/*
+Package manifest Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Supported manifest kinds
+const (
+	KindProject           = "Project"
+	KindVariable          = "Variable"
+	KindEndpoint          = "Endpoint"
+	KindCustomIntegration = "CustomIntegration"
+	KindPropertyGroup     = "PropertyGroup"
+	KindPackage           = "Package"
+	KindAction            = "Action"
+	KindWorkflow          = "Workflow"
+	KindCloudTemplate     = "CloudTemplate"
+	KindPipeline          = "Pipeline"
+)
+
+// ErrNotManifest is returned for files that are YAML or JSON, but not manifests
+var ErrNotManifest = errors.New("not a manifest")
+
+// kindOrder - the order kinds are applied in, so that objects are created before the objects that reference them
+var kindOrder = []string{
+	KindProject,
+	KindVariable,
+	KindEndpoint,
+	KindCustomIntegration,
+	KindPropertyGroup,
+	KindPackage,
+	KindAction,
+	KindWorkflow,
+	KindCloudTemplate,
+	KindPipeline,
+}
+
+// Document - a single object in a manifest
+type Document struct {
+	Kind    string                 // Normalised kind, e.g. Pipeline
+	Name    string                 // Object name
+	Project string                 // Object project, if any
+	Source  string                 // File the document was read from
+	Index   int                    // Position of the document in the source file
+	Raw     []byte                 // YAML of the document
+	Fields  map[string]interface{} // Decoded document
+}
+
+// String returns a description of the document for logging
+func (d *Document) String() string {
+	return fmt.Sprintf("%s %q (%s #%d)", d.Kind, d.Name, filepath.Base(d.Source), d.Index+1)
+}
+
+// Decode decodes the document into v, using v's JSON field tags
+func (d *Document) Decode(v interface{}) error {
+	jsonBytes, err := json.Marshal(d.Fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, v)
+}
+
+// Field returns a field of the document as a string
+func (d *Document) Field(field string) string {
+	if v, ok := d.Fields[field]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// File returns the path of a file referenced by the document, relative to the document's source
+func (d *Document) File(field string) string {
+	file := d.Field(field)
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(d.Source), file)
+}
+
+// SetProject moves the document to another project. A Project document is renamed.
+func (d *Document) SetProject(project string) error {
+	if d.Kind == KindProject {
+		return d.Rename(project)
+	} else if d.Project == "" {
+		return nil
+	}
+	d.Project = project
+	d.Fields["project"] = project
+	return d.marshal()
+}
+
+// Rename changes the name of the object the document describes
+func (d *Document) Rename(name string) error {
+	d.Name = name
+	d.Fields["name"] = name
+	return d.marshal()
+}
+
+// marshal updates Raw after a change to Fields
+func (d *Document) marshal() error {
+	raw, err := yaml.Marshal(d.Fields)
+	if err != nil {
+		return err
+	}
+	d.Raw = raw
+	return nil
+}
+
+// New returns a document describing an object, using the object's JSON field tags
+func New(kind, source string, object interface{}) (*Document, error) {
+	jsonBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil, err
+	}
+	fields["kind"] = kind
+	d := &Document{Kind: kind, Source: source, Fields: fields}
+	d.Name = d.Field("name")
+	d.Project = d.Field("project")
+	if d.Name == "" {
+		return nil, fmt.Errorf("%s: %s has no name", source, kind)
+	}
+	return d, d.marshal()
+}
+
+// NormaliseKind maps the kinds used by Code Stream exports (e.g. PIPELINE) and manifests to a supported kind
+func NormaliseKind(kind string) (string, bool) {
+	key := strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(kind))
+	for _, k := range kindOrder {
+		if strings.ToLower(k) == key {
+			return k, true
+		}
+	}
+	if key == "blueprint" {
+		return KindCloudTemplate, true
+	}
+	return "", false
+}
+
+// Load reads the manifests in a file, or in all .yaml, .yml and .json files in a directory and its subdirectories
+func Load(path string) ([]*Document, error) {
+	var files []string
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, file)
+		default:
+			if file == path {
+				files = append(files, file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []*Document
+	for _, file := range files {
+		fileDocuments, err := LoadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, fileDocuments...)
+	}
+	return documents, nil
+}
+
+// LoadFile reads the documents in a multi-document YAML (or JSON) file
+func LoadFile(file string) ([]*Document, error) {
+	fileBytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(file, fileBytes)
+}
+
+// LoadTree reads the manifests in all .yaml, .yml and .json files under a directory, skipping hidden
+// directories and files that are not manifests, such as Cloud Template content referenced by a manifest
+func LoadTree(path string) ([]*Document, error) {
+	var documents []*Document
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if file != path && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		fileDocuments, err := LoadFile(file)
+		if errors.Is(err, ErrNotManifest) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		documents = append(documents, fileDocuments...)
+		return nil
+	})
+	return documents, err
+}
+
+// Parse reads the documents in the content of a multi-document YAML (or JSON) file
+func Parse(file string, fileBytes []byte) ([]*Document, error) {
+	var documents []*Document
+	decoder := yaml.NewDecoder(bytes.NewReader(fileBytes))
+	for index := 0; ; index++ {
+		var content interface{}
+		if err := decoder.Decode(&content); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", file, index+1, err)
+		}
+		if content == nil { // Empty document, e.g. a leading ---
+			index--
+			continue
+		}
+		fields, ok := stringKeys(content).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: document %d is not a map: %w", file, index+1, ErrNotManifest)
+		}
+		if _, ok := fields["kind"]; !ok {
+			return nil, fmt.Errorf("%s: document %d has no kind: %w", file, index+1, ErrNotManifest)
+		}
+		kind, ok := NormaliseKind(fmt.Sprint(fields["kind"]))
+		if !ok {
+			return nil, fmt.Errorf("%s: document %d has an unsupported kind %q", file, index+1, fields["kind"])
+		}
+		raw, err := yaml.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		document := &Document{
+			Kind:   kind,
+			Source: file,
+			Index:  index,
+			Raw:    raw,
+			Fields: fields,
+		}
+		document.Name = document.Field("name")
+		document.Project = document.Field("project")
+		if document.Name == "" && document.Field("file") != "" {
+			document.Name = filepath.Base(document.Field("file"))
+		}
+		if document.Name == "" {
+			return nil, fmt.Errorf("%s: document %d (%s) has no name", file, index+1, kind)
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// stringKeys converts the map[interface{}]interface{} values produced by yaml.v2 to map[string]interface{}
+func stringKeys(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[fmt.Sprint(k)] = stringKeys(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range value {
+			value[i] = stringKeys(item)
+		}
+	}
+	return v
+}
+
+// Sort orders the documents so that dependencies are applied first: by kind, and then
+// pipelines after the pipelines they run as nested pipeline tasks
+func Sort(documents []*Document) error {
+	rank := make(map[string]int)
+	for i, k := range kindOrder {
+		rank[k] = i
+	}
+	sort.SliceStable(documents, func(i, j int) bool {
+		return rank[documents[i].Kind] < rank[documents[j].Kind]
+	})
+
+	// Order the pipelines by their nested pipeline dependencies
+	first := len(documents)
+	pipelines := make(map[string]*Document)
+	for i, d := range documents {
+		if d.Kind == KindPipeline {
+			if i < first {
+				first = i
+			}
+			pipelines[d.Project+"/"+d.Name] = d
+		}
+	}
+	var ordered []*Document
+	state := make(map[*Document]int) // 1 = visiting, 2 = done
+	var visit func(d *Document) error
+	visit = func(d *Document) error {
+		switch state[d] {
+		case 1:
+			return errors.New("pipeline " + d.Name + " has a circular nested pipeline dependency")
+		case 2:
+			return nil
+		}
+		state[d] = 1
+		for _, dependency := range PipelineDependencies(d) {
+			if p, ok := pipelines[d.Project+"/"+dependency]; ok {
+				if err := visit(p); err != nil {
+					return err
+				}
+			}
+		}
+		state[d] = 2
+		ordered = append(ordered, d)
+		return nil
+	}
+	for _, d := range documents[first:] {
+		if err := visit(d); err != nil {
+			return err
+		}
+	}
+	copy(documents[first:], ordered)
+	return nil
+}
+
+// PipelineDependencies returns the names of the pipelines a pipeline runs as nested pipeline tasks
+func PipelineDependencies(d *Document) []string {
+	var dependencies []string
+	stages, _ := d.Fields["stages"].(map[string]interface{})
+	for _, stage := range stages {
+		s, _ := stage.(map[string]interface{})
+		tasks, _ := s["tasks"].(map[string]interface{})
+		for _, task := range tasks {
+			t, _ := task.(map[string]interface{})
+			if fmt.Sprint(t["type"]) != "Pipeline" {
+				continue
+			}
+			if input, ok := t["input"].(map[string]interface{}); ok && input["pipeline"] != nil {
+				dependencies = append(dependencies, fmt.Sprint(input["pipeline"]))
+			}
+		}
+	}
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// Levels groups the documents into levels that can be applied concurrently, returning the indexes of the
+// documents in each level in the order they should be applied: by kind, and then pipelines after the
+// pipelines they run as nested pipeline tasks. Within a level, the documents keep their order.
+func Levels(documents []*Document) [][]int {
+	rank := make(map[string]int)
+	for i, k := range kindOrder {
+		rank[k] = i
+	}
+	pipelines := make(map[string]*Document)
+	for _, d := range documents {
+		if d.Kind == KindPipeline {
+			pipelines[d.Project+"/"+d.Name] = d
+		}
+	}
+	depths := make(map[*Document]int)
+	var depth func(d *Document) int
+	depth = func(d *Document) int {
+		if n, ok := depths[d]; ok {
+			return n
+		}
+		depths[d] = 0 // Guards against circular dependencies, which Sort reports
+		n := 0
+		for _, dependency := range PipelineDependencies(d) {
+			if p, ok := pipelines[d.Project+"/"+dependency]; ok && p != d {
+				if pn := depth(p) + 1; pn > n {
+					n = pn
+				}
+			}
+		}
+		depths[d] = n
+		return n
+	}
+
+	type level struct{ rank, depth int }
+	indexes := make(map[level][]int)
+	var keys []level
+	for i, d := range documents {
+		key := level{rank: rank[d.Kind]}
+		if d.Kind == KindPipeline {
+			key.depth = depth(d)
+		}
+		if _, ok := indexes[key]; !ok {
+			keys = append(keys, key)
+		}
+		indexes[key] = append(indexes[key], i)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].rank != keys[j].rank {
+			return keys[i].rank < keys[j].rank
+		}
+		return keys[i].depth < keys[j].depth
+	})
+	var levels [][]int
+	for _, key := range keys {
+		levels = append(levels, indexes[key])
+	}
+	return levels
+}
@@ -0,0 +1,64 @@
+/*
+Package manifest Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package manifest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testManifest = `---
+project: Field Demo
+kind: PIPELINE
+name: Deploy
+stages:
+  Deploy:
+    tasks:
+      Build:
+        type: Pipeline
+        input:
+          pipeline: Build
+---
+kind: Variable
+project: Field Demo
+name: region
+type: REGULAR
+value: eu-west-1
+---
+project: Field Demo
+kind: PIPELINE
+name: Build
+---
+kind: Project
+name: Field Demo
+`
+
+func TestLoadAndSort(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(testManifest), 0600))
+
+	documents, err := Load(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(documents), 4)
+	assert.NilError(t, Sort(documents))
+
+	var order []string
+	for _, d := range documents {
+		order = append(order, d.Kind+"/"+d.Name)
+	}
+	assert.DeepEqual(t, order, []string{"Project/Field Demo", "Variable/region", "Pipeline/Build", "Pipeline/Deploy"})
+	assert.Equal(t, documents[1].Field("value"), "eu-west-1")
+}
+
+func TestLevels(t *testing.T) {
+	documents, err := Parse("manifest.yaml", []byte(testManifest))
+	assert.NilError(t, err)
+
+	// Deploy, region, Build, Field Demo
+	assert.DeepEqual(t, Levels(documents), [][]int{{3}, {1}, {2}, {0}})
+}
@@ -0,0 +1,147 @@
+/*
+Package pipelineexecution Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package pipelineexecution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Execution statuses that end an Execution
+const (
+	Completed         = "COMPLETED"
+	Failed            = "FAILED"
+	Canceled          = "CANCELED"
+	RollbackCompleted = "ROLLBACK_COMPLETED"
+	RollbackFailed    = "ROLLBACK_FAILED"
+)
+
+// Exit codes of commands that follow an Execution
+const (
+	ExitCompleted   = 0
+	ExitFailed      = 1
+	ExitCanceled    = 2
+	ExitRolledBack  = 3
+	ExitTimeout     = 4 // The Execution did not end before the timeout
+	ExitUnavailable = 5 // The status of the Execution could not be read
+)
+
+// ErrTimeout is returned by Watch when the Execution does not end before the timeout
+var ErrTimeout = errors.New("timed out")
+
+// MaxPollErrors is the number of polls in a row that can fail before Watch gives up
+const MaxPollErrors = 3
+
+// Finished returns true if an Execution with the status has ended
+func Finished(status string) bool {
+	return ExitCode(status) >= 0
+}
+
+// ExitCode returns the exit code for the status of an Execution that has ended: 0 if it completed,
+// 1 if it failed, 2 if it was canceled and 3 if it was rolled back. It returns -1 if the Execution has not ended.
+func ExitCode(status string) int {
+	switch strings.ToUpper(status) {
+	case Completed:
+		return ExitCompleted
+	case Failed:
+		return ExitFailed
+	case Canceled:
+		return ExitCanceled
+	case RollbackCompleted, RollbackFailed:
+		return ExitRolledBack
+	}
+	return -1
+}
+
+// Watch polls an Execution every interval until it ends, calling update with each poll. A poll that fails is
+// retried, until MaxPollErrors polls in a row have failed. If timeout is not zero and the Execution has not
+// ended after timeout, it returns the last state of the Execution with an error that wraps ErrTimeout.
+func Watch(poll func() (*types.Executions, error), interval, timeout time.Duration, update func(*types.Executions)) (*types.Executions, error) {
+	deadline := time.Now().Add(timeout)
+	var execution *types.Executions
+	failures := 0
+	for {
+		latest, err := poll()
+		if err != nil {
+			if failures++; failures >= MaxPollErrors {
+				return execution, err
+			}
+			log.Warnln("Unable to get the execution, retrying:", err)
+		} else {
+			failures = 0
+			execution = latest
+			update(execution)
+			if Finished(execution.Status) {
+				return execution, nil
+			}
+		}
+		wait := interval
+		if timeout > 0 {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				if execution == nil {
+					return nil, fmt.Errorf("%w after %s, unable to get the execution: %s", ErrTimeout, timeout, err)
+				}
+				return execution, fmt.Errorf("%w after %s, execution %s#%d is still %s", ErrTimeout, timeout, execution.Name, execution.Index, execution.Status)
+			}
+			if remaining < wait { // Poll once more at the deadline
+				wait = remaining
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Stages returns the stages of an Execution, in the order they run
+func Stages(execution *types.Executions) ([]*types.ExecutionStage, error) {
+	stagesBytes, err := json.Marshal(execution.Stages)
+	if err != nil {
+		return nil, err
+	}
+	stages := make(map[string]*types.ExecutionStage)
+	if err := json.Unmarshal(stagesBytes, &stages); err != nil {
+		return nil, err
+	}
+	var ordered []*types.ExecutionStage
+	for _, name := range execution.StageOrder {
+		if stage, ok := stages[fmt.Sprint(name)]; ok && stage != nil {
+			if stage.Name == "" {
+				stage.Name = fmt.Sprint(name)
+			}
+			ordered = append(ordered, stage)
+		}
+	}
+	return ordered, nil
+}
+
+// TaskNames returns the names of the tasks in a stage, in the order they run. Tasks that
+// run in parallel are listed in the order they appear in the stage's task order.
+func TaskNames(stage *types.ExecutionStage) []string {
+	var names []string
+	for _, step := range stage.TaskOrder {
+		for _, task := range strings.Split(step, ",") {
+			if task = strings.TrimSpace(task); task != "" {
+				names = append(names, task)
+			}
+		}
+	}
+	return names
+}
+
+// Output returns an output of an Execution, and false if the Execution does not have the output
+func Output(execution *types.Executions, name string) (interface{}, bool) {
+	outputs, ok := execution.Output.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := outputs[name]
+	return value, ok
+}
@@ -0,0 +1,191 @@
+/*
+Package pipelineexecution Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package pipelineexecution
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	"gotest.tools/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		status   string
+		code     int
+		finished bool
+	}{
+		{status: "COMPLETED", code: 0, finished: true},
+		{status: "completed", code: 0, finished: true},
+		{status: "FAILED", code: 1, finished: true},
+		{status: "CANCELED", code: 2, finished: true},
+		{status: "ROLLBACK_COMPLETED", code: 3, finished: true},
+		{status: "ROLLBACK_FAILED", code: 3, finished: true},
+		{status: "RUNNING", code: -1},
+		{status: "WAITING", code: -1},
+		{status: "PAUSED", code: -1},
+		{status: "", code: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, ExitCode(tt.status), tt.code)
+			assert.Equal(t, Finished(tt.status), tt.finished)
+		})
+	}
+}
+
+func TestTaskNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		taskOrder []string
+		want      []string
+	}{
+		{name: "none", taskOrder: nil, want: nil},
+		{name: "sequential", taskOrder: []string{"Build", "Test"}, want: []string{"Build", "Test"}},
+		{name: "parallel", taskOrder: []string{"Build", "Unit,Integration", "Deploy"}, want: []string{"Build", "Unit", "Integration", "Deploy"}},
+		{name: "spaces", taskOrder: []string{" Unit , Integration ", ",", ""}, want: []string{"Unit", "Integration"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, TaskNames(&types.ExecutionStage{TaskOrder: tt.taskOrder}), tt.want)
+		})
+	}
+}
+
+func TestStages(t *testing.T) {
+	stages := map[string]interface{}{
+		"Deploy": map[string]interface{}{"name": "Deploy", "status": "NOT_STARTED"},
+		"Build":  map[string]interface{}{"status": "COMPLETED", "taskOrder": []interface{}{"Compile"}},
+		"Unused": map[string]interface{}{"name": "Unused"},
+	}
+	tests := []struct {
+		name       string
+		stageOrder []interface{}
+		stages     interface{}
+		want       []string
+	}{
+		{name: "in stage order", stageOrder: []interface{}{"Build", "Deploy"}, stages: stages, want: []string{"Build:COMPLETED", "Deploy:NOT_STARTED"}},
+		{name: "missing stages", stageOrder: []interface{}{"Test", "Deploy"}, stages: stages, want: []string{"Deploy:NOT_STARTED"}},
+		{name: "no stage order", stageOrder: nil, stages: stages, want: nil},
+		{name: "no stages", stageOrder: []interface{}{"Build"}, stages: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Stages(&types.Executions{StageOrder: tt.stageOrder, Stages: tt.stages})
+			assert.NilError(t, err)
+			var names []string
+			for _, stage := range got {
+				names = append(names, stage.Name+":"+stage.Status)
+			}
+			assert.DeepEqual(t, names, tt.want)
+		})
+	}
+
+	_, err := Stages(&types.Executions{StageOrder: []interface{}{"Build"}, Stages: []interface{}{"Build"}})
+	assert.ErrorContains(t, err, "cannot unmarshal")
+}
+
+// pollResults returns a poll function that returns each of the results in turn
+func pollResults(results ...interface{}) (func() (*types.Executions, error), *int) {
+	count := 0
+	return func() (*types.Executions, error) {
+		result := results[count]
+		count++
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+		return &types.Executions{Name: "Deploy", Status: result.(string)}, nil
+	}, &count
+}
+
+func TestWatch(t *testing.T) {
+	transient := errors.New("connection reset")
+	tests := []struct {
+		name    string
+		results []interface{}
+		status  string
+		err     string
+		updates int
+	}{
+		{name: "completes", results: []interface{}{"RUNNING", "RUNNING", "COMPLETED"}, status: "COMPLETED", updates: 3},
+		{name: "fails", results: []interface{}{"RUNNING", "FAILED"}, status: "FAILED", updates: 2},
+		{name: "retries transient errors", results: []interface{}{"RUNNING", transient, transient, "RUNNING", transient, "COMPLETED"}, status: "COMPLETED", updates: 3},
+		{name: "gives up", results: []interface{}{"RUNNING", transient, transient, transient}, status: "RUNNING", err: "connection reset", updates: 1},
+		{name: "gives up before the first poll", results: []interface{}{transient, transient, transient}, err: "connection reset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll, count := pollResults(tt.results...)
+			updates := 0
+			execution, err := Watch(poll, time.Millisecond, 0, func(*types.Executions) { updates++ })
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				assert.NilError(t, err)
+			}
+			if tt.status == "" {
+				assert.Assert(t, execution == nil)
+			} else {
+				assert.Equal(t, execution.Status, tt.status)
+			}
+			assert.Equal(t, updates, tt.updates)
+			assert.Equal(t, *count, len(tt.results))
+		})
+	}
+}
+
+func TestWatchTimeout(t *testing.T) {
+	running := func() (*types.Executions, error) {
+		return &types.Executions{Name: "Deploy", Index: 3, Status: "RUNNING"}, nil
+	}
+	// The last poll is at the deadline, even when it is less than an interval away
+	start := time.Now()
+	var lastPoll time.Time
+	execution, err := Watch(running, 40*time.Millisecond, 100*time.Millisecond, func(*types.Executions) { lastPoll = time.Now() })
+	assert.Assert(t, errors.Is(err, ErrTimeout), err)
+	assert.ErrorContains(t, err, "Deploy#3 is still RUNNING")
+	assert.Equal(t, execution.Status, "RUNNING")
+	assert.Assert(t, lastPoll.Sub(start) >= 100*time.Millisecond, lastPoll.Sub(start))
+	assert.Assert(t, time.Since(start) < time.Second, time.Since(start))
+
+	// An interval longer than the timeout still waits for the timeout
+	start = time.Now()
+	_, err = Watch(running, time.Hour, 50*time.Millisecond, func(*types.Executions) {})
+	assert.Assert(t, errors.Is(err, ErrTimeout), err)
+	assert.Assert(t, time.Since(start) >= 50*time.Millisecond)
+
+	// An Execution that ends at the deadline is not a timeout
+	poll, _ := pollResults("RUNNING", "COMPLETED")
+	execution, err = Watch(poll, time.Hour, 10*time.Millisecond, func(*types.Executions) {})
+	assert.NilError(t, err)
+	assert.Equal(t, execution.Status, "COMPLETED")
+}
+
+func TestOutput(t *testing.T) {
+	outputs := map[string]interface{}{"url": "https://example.com", "replicas": float64(3), "empty": "", "none": nil}
+	tests := []struct {
+		name   string
+		output interface{}
+		want   interface{}
+		found  bool
+	}{
+		{name: "url", output: outputs, want: "https://example.com", found: true},
+		{name: "replicas", output: outputs, want: float64(3), found: true},
+		{name: "empty", output: outputs, want: "", found: true},
+		{name: "none", output: outputs, want: nil, found: true},
+		{name: "missing", output: outputs, want: nil, found: false},
+		{name: "url", output: nil, want: nil, found: false},
+		{name: "url", output: []interface{}{"url"}, want: nil, found: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := Output(&types.Executions{Output: tt.output}, tt.name)
+			assert.Equal(t, found, tt.found)
+			assert.DeepEqual(t, value, tt.want)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Package pipelineinput Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package pipelineinput
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads Pipeline inputs from a JSON, YAML or dotenv (.env) file
+func Load(file string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if IsDotenv(file) {
+		return ParseDotenv(content)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil { // JSON is also YAML
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return Strings(values)
+}
+
+// IsDotenv returns true if a file is a dotenv file, e.g. .env or prod.env
+func IsDotenv(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".env") || strings.EqualFold(filepath.Base(file), ".env")
+}
+
+// ParseDotenv reads KEY=value lines. Blank lines and lines starting with # are ignored, an export prefix
+// is allowed, and values can be single or double quoted.
+func ParseDotenv(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("line %d is not KEY=value", line)
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// uuid matches an ID, given to -i when it was the shorthand for --id
+var uuid = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ParseOverrides reads key=value pairs
+func ParseOverrides(pairs []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 && uuid.MatchString(pair) {
+			return nil, fmt.Errorf("input %q is not key=value - -i is the shorthand for --input, use --id %s", pair, pair)
+		}
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("input %q is not key=value", pair)
+		}
+		values[parts[0]] = parts[1]
+	}
+	return values, nil
+}
+
+// Strings converts input values to strings, the type of every Pipeline input. Maps and lists are not allowed.
+func Strings(values map[string]interface{}) (map[string]string, error) {
+	inputs := make(map[string]string)
+	for key, value := range values {
+		switch v := value.(type) {
+		case nil:
+			inputs[key] = ""
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("input %s must be a string, not a map or list", key)
+		case float64: // JSON numbers, which would otherwise be printed in exponent form (1e+06)
+			inputs[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			inputs[key] = fmt.Sprint(v)
+		}
+	}
+	return inputs, nil
+}
+
+// Merge merges sets of inputs, with values from later sets taking precedence
+func Merge(sets ...map[string]string) map[string]string {
+	inputs := make(map[string]string)
+	for _, set := range sets {
+		for key, value := range set {
+			inputs[key] = value
+		}
+	}
+	return inputs
+}
+
+// Validate returns an error if an input is not declared by the Pipeline. declared is the Pipeline's
+// input, with the default value of each input.
+func Validate(inputs map[string]string, declared map[string]interface{}) error {
+	unknown := Undeclared(inputs, declared)
+	if len(unknown) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(declared))
+	for key := range declared {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return fmt.Errorf("the Pipeline has no inputs, but %s were given", strings.Join(unknown, ", "))
+	}
+	return fmt.Errorf("the Pipeline has no input %s, must be one of %s", strings.Join(unknown, ", "), strings.Join(names, ", "))
+}
+
+// Undeclared returns the names of the inputs that are not declared by the Pipeline
+func Undeclared(inputs map[string]string, declared map[string]interface{}) []string {
+	var unknown []string
+	for key := range inputs {
+		if _, ok := declared[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// Rerun returns the inputs to rerun an execution with - the execution's original inputs, without those
+// the Pipeline no longer declares, and the overrides, which must be declared by the Pipeline
+func Rerun(original map[string]string, overrides map[string]string, declared map[string]interface{}) (map[string]string, error) {
+	if err := Validate(overrides, declared); err != nil {
+		return nil, err
+	}
+	inputs := Merge(original)
+	for _, key := range Undeclared(inputs, declared) {
+		delete(inputs, key)
+	}
+	return Merge(inputs, overrides), nil
+}
+
+// Missing returns the names of the Pipeline's inputs that have no default value (null) and were not given.
+// An input with an empty default value is optional, and uses the empty value.
+func Missing(inputs map[string]string, declared map[string]interface{}) []string {
+	var missing []string
+	for key, def := range declared {
+		if _, ok := inputs[key]; ok {
+			continue
+		}
+		if def == nil {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
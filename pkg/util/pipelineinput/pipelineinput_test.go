@@ -0,0 +1,134 @@
+/*
+Package pipelineinput Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package pipelineinput
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"inputs.json": `{"branch": "main", "replicas": 3}`,
+		"inputs.yaml": "branch: main\nreplicas: 3\n",
+		"prod.env":    "# Production\nexport branch=\"main\"\nreplicas='3'\n\n",
+	}
+	want := map[string]string{"branch": "main", "replicas": "3"}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(file)
+		if err != nil {
+			t.Fatalf("Load(%s) error: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Load(%s) = %v, want %v", name, got, want)
+		}
+	}
+	if _, err := ParseDotenv([]byte("branch\n")); err == nil {
+		t.Error("ParseDotenv() of a line without = should fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	declared := map[string]interface{}{"branch": "main", "version": "", "token": nil}
+	overrides, err := ParseOverrides([]string{"branch=dev", "version=1.0=rc1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := Merge(map[string]string{"branch": "main"}, overrides)
+	if want := map[string]string{"branch": "dev", "version": "1.0=rc1"}; !reflect.DeepEqual(inputs, want) {
+		t.Errorf("Merge() = %v, want %v", inputs, want)
+	}
+	if err := Validate(inputs, declared); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+	if got := Missing(inputs, declared); !reflect.DeepEqual(got, []string{"token"}) {
+		t.Errorf("Missing() = %v, want [token]", got)
+	}
+	// Inputs with an empty default value are optional
+	if got := Missing(map[string]string{}, declared); !reflect.DeepEqual(got, []string{"token"}) {
+		t.Errorf("Missing() = %v, want [token]", got)
+	}
+	if got := Missing(map[string]string{"token": ""}, declared); got != nil {
+		t.Errorf("Missing() = %v, want none", got)
+	}
+	if err := Validate(map[string]string{"brnach": "dev"}, declared); err == nil {
+		t.Error("Validate() of an undeclared input should fail")
+	}
+	if _, err := ParseOverrides([]string{"branch"}); err == nil {
+		t.Error("ParseOverrides() of a value without = should fail")
+	}
+	_, err = ParseOverrides([]string{"71dcc4aa-fa43-4e66-a9c6-4d1d6da2a4ab"})
+	if err == nil || !strings.Contains(err.Error(), "use --id 71dcc4aa-fa43-4e66-a9c6-4d1d6da2a4ab") {
+		t.Errorf("ParseOverrides() of an ID should suggest --id, got %v", err)
+	}
+	if _, err := ParseOverrides([]string{"id=71dcc4aa-fa43-4e66-a9c6-4d1d6da2a4ab"}); err != nil {
+		t.Errorf("ParseOverrides() of an ID value error: %v", err)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"count": 1000000, "id": 12345678901234, "ratio": 0.25, "enabled": true, "name": "web", "empty": null}`), &values); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Strings(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"count": "1000000", "id": "12345678901234", "ratio": "0.25", "enabled": "true", "name": "web", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Strings() = %v, want %v", got, want)
+	}
+	if _, err := Strings(map[string]interface{}{"tags": []interface{}{"a"}}); err == nil {
+		t.Error("Strings() of a list should fail")
+	}
+}
+
+func TestRerun(t *testing.T) {
+	declared := map[string]interface{}{"branch": "main", "version": "", "token": nil}
+	original := map[string]string{"branch": "dev", "version": "1.0", "region": "eu"}
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		want      map[string]string
+		err       bool
+	}{
+		{name: "original", want: map[string]string{"branch": "dev", "version": "1.0"}},
+		{name: "override", overrides: map[string]string{"version": "1.1"}, want: map[string]string{"branch": "dev", "version": "1.1"}},
+		{name: "new input", overrides: map[string]string{"token": "secret"}, want: map[string]string{"branch": "dev", "version": "1.0", "token": "secret"}},
+		{name: "empty override", overrides: map[string]string{"branch": ""}, want: map[string]string{"branch": "", "version": "1.0"}},
+		{name: "undeclared override", overrides: map[string]string{"region": "us"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Rerun(original, tt.overrides, declared)
+			if tt.err {
+				if err == nil {
+					t.Errorf("Rerun() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rerun() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rerun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, ok := original["region"]; !ok {
+		t.Error("Rerun() modified the original inputs")
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Package plugin Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Prefix - executables named Prefix<name> on the PATH are run as `vra-cli <name>`
+const Prefix = "vra-cli-"
+
+// Plugin - an external vra-cli command
+type Plugin struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Shadowed []string `json:"shadowed,omitempty"` // Plugins with the same name later in the PATH
+}
+
+// Find returns the plugins on the PATH, in PATH order. When more than one
+// executable has the same name, the first one found is used.
+func Find() []*Plugin {
+	var plugins []*Plugin
+	found := make(map[string]*Plugin)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			name, ok := pluginName(f)
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, f.Name())
+			if p, ok := found[name]; ok {
+				if p.Path != path {
+					p.Shadowed = append(p.Shadowed, path)
+				}
+				continue
+			}
+			p := &Plugin{Name: name, Path: path}
+			found[name] = p
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// Lookup returns the first plugin on the PATH with a name, or nil if there is none
+func Lookup(name string) *Plugin {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil
+	}
+	files := []string{Prefix + name}
+	if runtime.GOOS == "windows" {
+		files = []string{Prefix + name + ".exe", Prefix + name + ".bat", Prefix + name + ".cmd"}
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		for _, file := range files {
+			path := filepath.Join(dir, file)
+			f, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if n, ok := pluginName(f); ok && n == name {
+				return &Plugin{Name: name, Path: path}
+			}
+		}
+	}
+	return nil
+}
+
+// pluginName returns the plugin name for an executable file, and false if the file is not a plugin
+func pluginName(f os.FileInfo) (string, bool) {
+	if f.IsDir() || !strings.HasPrefix(f.Name(), Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(f.Name(), Prefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	} else if f.Mode()&0111 == 0 {
+		return "", false
+	}
+	return name, name != ""
+}
+
+// Environment returns the variables that pass the resolved vra-cli session to a plugin
+func Environment(APIClient *types.APIClientOptions) []string {
+	env := []string{
+		"VRA_CLI_OUTPUT=" + APIClient.Output,
+		"VRA_CLI_API_VERSION=" + APIClient.Version,
+		"VRA_CLI_DEBUG=" + strconv.FormatBool(APIClient.Debug),
+		"VRA_CLI_INSECURE=" + strconv.FormatBool(APIClient.VerifySSL),
+	}
+	if APIClient.Config != nil {
+		env = append(env,
+			"VRA_CLI_TARGET="+APIClient.Config.Name,
+			"VRA_CLI_SERVER="+APIClient.Config.Server,
+			"VRA_CLI_ACCESS_TOKEN="+APIClient.Config.AccessToken,
+			// Allow the plugin to call vra-cli with the same session
+			"VRA_SERVER="+APIClient.Config.Server,
+			"VRA_ACCESSTOKEN="+APIClient.Config.AccessToken,
+		)
+	}
+	return env
+}
+
+// Run executes a plugin with the arguments and the vra-cli session in its environment,
+// returning the plugin's exit code
+func Run(APIClient *types.APIClientOptions, p *Plugin, args []string) (int, error) {
+	log.Debugln("Running plugin", p.Name, p.Path, args)
+	command := exec.Command(p.Path, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = append(os.Environ(), Environment(APIClient)...)
+	if err := command.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, err
+	}
+	return 0, nil
+}
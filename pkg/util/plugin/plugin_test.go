@@ -0,0 +1,87 @@
+/*
+Package plugin Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// setPath creates directories of files for the PATH, with the files ending in "*" executable
+func setPath(t *testing.T, dirs ...[]string) []string {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins on Windows are found by extension")
+	}
+	var paths []string
+	for _, files := range dirs {
+		dir := t.TempDir()
+		for _, file := range files {
+			mode := os.FileMode(0644)
+			if strings.HasSuffix(file, "*") {
+				file, mode = strings.TrimSuffix(file, "*"), 0755
+			}
+			if strings.HasSuffix(file, "/") {
+				assert.NilError(t, os.Mkdir(filepath.Join(dir, file), 0755))
+				continue
+			}
+			assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("#!/bin/sh\n"), mode))
+		}
+		paths = append(paths, dir)
+	}
+	t.Setenv("PATH", strings.Join(paths, string(os.PathListSeparator)))
+	return paths
+}
+
+func TestFind(t *testing.T) {
+	paths := setPath(t,
+		[]string{"vra-cli-report*", "vra-cli-notes", "vra-cli-dir/", "vra-cli-*", "kubectl*"},
+		[]string{"vra-cli-report*", "vra-cli-sync*"},
+	)
+	plugins := Find()
+	assert.DeepEqual(t, plugins, []*Plugin{
+		{
+			Name:     "report",
+			Path:     filepath.Join(paths[0], "vra-cli-report"),
+			Shadowed: []string{filepath.Join(paths[1], "vra-cli-report")},
+		},
+		{Name: "sync", Path: filepath.Join(paths[1], "vra-cli-sync")},
+	})
+}
+
+func TestFindEmptyPath(t *testing.T) {
+	t.Setenv("PATH", filepath.Join(t.TempDir(), "missing"))
+	assert.Equal(t, len(Find()), 0)
+}
+
+func TestLookup(t *testing.T) {
+	paths := setPath(t,
+		[]string{"vra-cli-notes", "vra-cli-dir/"},
+		[]string{"vra-cli-report*", "vra-cli-notes*"},
+		[]string{"vra-cli-report*"},
+	)
+	tests := []struct {
+		name string
+		want *Plugin
+	}{
+		{name: "report", want: &Plugin{Name: "report", Path: filepath.Join(paths[1], "vra-cli-report")}},
+		// Files that are not executable are skipped
+		{name: "notes", want: &Plugin{Name: "notes", Path: filepath.Join(paths[1], "vra-cli-notes")}},
+		{name: "dir", want: nil},
+		{name: "missing", want: nil},
+		{name: "", want: nil},
+		{name: "../" + filepath.Base(paths[1]) + "/vra-cli-report", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, Lookup(tt.name), tt.want)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Package schema Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sammcgeown/vra-cli/pkg/util/types"
+)
+
+// Schema - a JSON Schema (draft-07), with the keywords used by vra-cli
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Types - the JSON types a value can have
+type Types []string
+
+// MarshalJSON writes a single type as a string
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Kinds - the kinds of object with a schema
+var Kinds = []string{"pipeline", "endpoint", "variable", "customintegration"}
+
+// TaskTypes - the task types supported by Code Stream
+var TaskTypes = []string{"Bamboo", "Blueprint", "CI", "Condition", "Custom", "Jenkins", "K8S", "Pipeline", "POLL", "PowerShell", "REST", "SSH", "TFS", "UserOperation", "VRO"}
+
+// taskInputs - the inputs of each task type, and their JSON types. Tasks can have other inputs.
+var taskInputs = map[string]map[string]string{
+	"Bamboo":        {"plan": "string", "planBranch": "string", "variables": "object"},
+	"Blueprint":     {"action": "string", "blueprintName": "string", "blueprintVersion": "string", "deploymentName": "string", "parameters": "object", "filepath": "string", "outputProperties": "object"},
+	"CI":            {"steps": "array", "export": "array", "artifacts": "array", "process": "array"},
+	"Condition":     {"condition": "string"},
+	"Custom":        {"name": "string", "version": "string", "properties": "object"},
+	"Jenkins":       {"job": "string", "jobFolder": "string", "parameters": "object"},
+	"K8S":           {"action": "string", "timeout": "integer", "filePath": "string", "yaml": "string", "continueOnConflict": "boolean"},
+	"Pipeline":      {"pipeline": "string", "inputProperties": "object"},
+	"POLL":          {"url": "string", "headers": "object", "pollCount": "integer", "pollIntervalSeconds": "integer", "ignoreFailure": "boolean", "exitCriteria": "object"},
+	"PowerShell":    {"host": "string", "username": "string", "password": "string", "script": "string", "workingDirectory": "string", "environmentVariables": "object", "arguments": "array", "continueOnCommandFailure": "boolean"},
+	"REST":          {"action": "string", "url": "string", "headers": "object", "payload": "string", "fingerprint": "string"},
+	"SSH":           {"host": "string", "username": "string", "password": "string", "privatekey": "string", "passphrase": "string", "script": "string", "workingDirectory": "string", "environmentVariables": "object", "arguments": "array", "continueOnCommandFailure": "boolean"},
+	"TFS":           {"projectCollection": "string", "teamProject": "string", "buildDefinitionId": "string", "parameters": "object"},
+	"UserOperation": {"approvers": "array", "approverGroups": "array", "summary": "string", "description": "string", "expirationInDays": "integer", "sendemail": "boolean"},
+	"VRO":           {"workflowId": "string", "parameters": "array"},
+}
+
+// For returns the schema of a kind of object (see Kinds)
+func For(kind string) (*Schema, error) {
+	var s *Schema
+	switch strings.ToLower(kind) {
+	case "pipeline":
+		s = pipeline()
+	case "endpoint":
+		s = Reflect(reflect.TypeOf(types.EndpointYaml{}))
+		s.Title = "Code Stream Endpoint"
+		s.Properties["kind"].Enum = []interface{}{"ENDPOINT", "Endpoint"}
+		s.Properties["properties"] = &Schema{Type: Types{"object", "null"}, Description: "Properties of the Endpoint type"}
+		s.Required = []string{"kind", "name", "type"}
+	case "variable":
+		s = Reflect(reflect.TypeOf(types.VariableRequest{}))
+		s.Title = "Code Stream Variable"
+		s.Properties["type"].Enum = []interface{}{"REGULAR", "SECRET", "RESTRICTED"}
+		s.Required = []string{"name", "type"}
+	case "customintegration":
+		s = Reflect(reflect.TypeOf(types.CustomIntegration{}))
+		s.Title = "Code Stream Custom Integration"
+		s.Required = []string{"name", "yaml"}
+	default:
+		return nil, fmt.Errorf("there is no schema for %s, must be one of %s", kind, strings.Join(Kinds, ", "))
+	}
+	s.Type = []string{"object"}
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.ID = "https://github.com/sammcgeown/vra-cli/schema/" + strings.ToLower(kind) + ".json"
+	return s, nil
+}
+
+// pipeline returns the schema of a Pipeline export, with the stages, tasks and task inputs
+func pipeline() *Schema {
+	s := Reflect(reflect.TypeOf(types.PipelineYaml{}))
+	s.Title = "Code Stream Pipeline"
+	s.Properties["kind"].Enum = []interface{}{"PIPELINE", "Pipeline"}
+	s.Required = []string{"kind", "name"}
+	s.Properties["input"] = &Schema{Type: []string{"object", "null"}, Description: "Pipeline inputs and their default values"}
+	workspace, _ := reflect.TypeOf(types.Pipeline{}).FieldByName("Workspace")
+	s.Properties["workspace"] = Reflect(workspace.Type)
+	s.Properties["stages"] = &Schema{
+		Type:                 []string{"object", "null"},
+		AdditionalProperties: &Schema{Ref: "#/definitions/stage"},
+	}
+
+	task := Reflect(reflect.TypeOf(types.PipelineTask{}))
+	task.Properties["type"] = &Schema{Type: []string{"string"}, Enum: enum(TaskTypes)}
+	task.Properties["input"] = &Schema{Type: []string{"object", "null"}}
+	task.Required = []string{"type"}
+	for _, taskType := range TaskTypes {
+		input := &Schema{Type: []string{"object", "null"}, Properties: make(map[string]*Schema)}
+		for name, jsonType := range taskInputs[taskType] {
+			input.Properties[name] = &Schema{Type: []string{jsonType, "null"}}
+			if jsonType != "string" { // Or a ${...} expression
+				input.Properties[name].Type = []string{jsonType, "string", "null"}
+			}
+		}
+		task.AllOf = append(task.AllOf, &Schema{
+			If:   &Schema{Properties: map[string]*Schema{"type": {Const: taskType}}},
+			Then: &Schema{Properties: map[string]*Schema{"input": input}},
+		})
+	}
+	s.Definitions = map[string]*Schema{
+		"stage": {
+			Type: []string{"object"},
+			Properties: map[string]*Schema{
+				"taskOrder": {Type: []string{"array", "null"}, Items: &Schema{Type: []string{"string"}}, Description: "Tasks in the order they run, with tasks that run in parallel separated by commas"},
+				"tasks":     {Type: []string{"object", "null"}, AdditionalProperties: &Schema{Ref: "#/definitions/task"}},
+				"tags":      {Type: []string{"array", "null"}, Items: &Schema{Type: []string{"string"}}},
+			},
+		},
+		"task": task,
+	}
+	return s
+}
+
+// Reflect returns the schema of a Go type, using the YAML (or JSON) names of struct fields.
+// Every field is optional, and can be null.
+func Reflect(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: []string{"string", "null"}}
+	case reflect.Bool:
+		return &Schema{Type: []string{"boolean", "null"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: []string{"integer", "null"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: []string{"number", "null"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: []string{"array", "null"}, Items: Reflect(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: Reflect(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: []string{"object", "null"}, Properties: make(map[string]*Schema)}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if name := fieldName(field); name != "" {
+				s.Properties[name] = Reflect(field.Type)
+			}
+		}
+		return s
+	}
+	return &Schema{} // Any value
+}
+
+// fieldName returns the YAML name of a struct field, or its JSON name if it has no YAML tag
+func fieldName(field reflect.StructField) string {
+	if field.PkgPath != "" { // Unexported
+		return ""
+	}
+	tag, ok := field.Tag.Lookup("yaml")
+	if !ok {
+		tag, ok = field.Tag.Lookup("json")
+	}
+	if !ok {
+		return field.Name
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func enum(values []string) []interface{} {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	var e []interface{}
+	for _, v := range sorted {
+		e = append(e, v)
+	}
+	return e
+}
@@ -0,0 +1,69 @@
+/*
+Package schema Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testPipeline = `project: Field Demo
+kind: PIPELINE
+name: Deploy
+concurrency: ten
+stages:
+  Build:
+    tasks:
+      Compile:
+        type: SSH
+        input:
+          script:
+            - make
+      Wait:
+        type: POLL
+        input:
+          pollCount: ${input.count}
+      Notify:
+        type: Slack
+`
+
+func TestValidatePipeline(t *testing.T) {
+	s, err := For("pipeline")
+	assert.NilError(t, err)
+	errs, err := s.ValidateYAML([]byte(testPipeline))
+	assert.NilError(t, err)
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Keyword+" "+e.Error())
+	}
+	assert.DeepEqual(t, got, []string{
+		"type concurrency: expected integer or null, found string",
+		"type stages.Build.tasks.Compile.input.script: expected string or null, found array",
+		`enum stages.Build.tasks.Notify.type: "Slack" is not one of Bamboo, Blueprint, CI, Condition, Custom, Jenkins, K8S, POLL, Pipeline, PowerShell, REST, SSH, TFS, UserOperation, VRO`,
+	})
+	assert.Equal(t, errs[1].Line, 12)
+	assert.Equal(t, len(errs[2].Path), 5)
+	for i, e := range errs {
+		assert.Equal(t, e.UnsupportedTaskType(), i == 2, e.Error())
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	for _, kind := range Kinds {
+		s, err := For(kind)
+		assert.NilError(t, err)
+		_, err = json.Marshal(s)
+		assert.NilError(t, err)
+	}
+	s, _ := For("variable")
+	errs, err := s.ValidateYAML([]byte("name: region\ntype: PLAIN\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(errs), 1)
+	_, err = For("workflow")
+	assert.ErrorContains(t, err, "no schema")
+}
@@ -0,0 +1,170 @@
+/*
+Package schema Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error - a value that does not match its schema
+type Error struct {
+	Path    []string // Keys and indexes from the root of the document to the value
+	Keyword string   // The schema keyword that failed, e.g. type or enum
+	Message string
+	Line    int
+	Column  int
+}
+
+// Error implements the error interface
+func (e Error) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	return strings.Join(e.Path, ".") + ": " + e.Message
+}
+
+// UnsupportedTaskType returns true if the error is a Pipeline task with a type that is not in TaskTypes.
+// Code Stream may support task types that vra-cli does not know about, so these are warnings, not errors.
+func (e Error) UnsupportedTaskType() bool {
+	return e.Keyword == "enum" && len(e.Path) == 5 && e.Path[0] == "stages" && e.Path[2] == "tasks" && e.Path[4] == "type"
+}
+
+// Validate validates a YAML document, or value, against the schema
+func (s *Schema) Validate(node *yaml.Node) []Error {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	v := &validator{root: s}
+	v.validate(s, node, nil)
+	return v.errors
+}
+
+// ValidateYAML validates the content of a YAML document against the schema
+func (s *Schema) ValidateYAML(content []byte) ([]Error, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+	return s.Validate(&node), nil
+}
+
+type validator struct {
+	root   *Schema
+	errors []Error
+}
+
+func (v *validator) fail(node *yaml.Node, path []string, keyword, format string, a ...interface{}) {
+	v.errors = append(v.errors, Error{
+		Path:    append([]string(nil), path...),
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, a...),
+		Line:    node.Line,
+		Column:  node.Column,
+	})
+}
+
+func (v *validator) validate(s *Schema, node *yaml.Node, path []string) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if s.Ref != "" {
+		s = v.root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if s == nil {
+			return
+		}
+	}
+
+	nodeType := jsonType(node)
+	if len(s.Type) > 0 && !matchesType(s.Type, nodeType) {
+		v.fail(node, path, "type", "expected %s, found %s", strings.Join(s.Type, " or "), nodeType)
+		return
+	}
+	if s.Const != nil && node.Value != fmt.Sprint(s.Const) {
+		v.fail(node, path, "const", "must be %v", s.Const)
+	}
+	if len(s.Enum) > 0 && nodeType != "null" {
+		found := false
+		var values []string
+		for _, e := range s.Enum {
+			values = append(values, fmt.Sprint(e))
+			if node.Value == fmt.Sprint(e) {
+				found = true
+			}
+		}
+		if !found {
+			v.fail(node, path, "enum", "%q is not one of %s", node.Value, strings.Join(values, ", "))
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		keys := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			keys[key] = true
+			if property, ok := s.Properties[key]; ok {
+				v.validate(property, value, append(path, key))
+			} else if s.AdditionalProperties != nil {
+				v.validate(s.AdditionalProperties, value, append(path, key))
+			}
+		}
+		for _, required := range s.Required {
+			if !keys[required] {
+				v.fail(node, path, "required", "%s is required", required)
+			}
+		}
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				v.validate(s.Items, item, append(path, strconv.Itoa(i)))
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		v.validate(sub, node, path)
+	}
+	if s.If != nil && s.Then != nil {
+		condition := &validator{root: v.root}
+		condition.validate(s.If, node, path)
+		if len(condition.errors) == 0 {
+			v.validate(s.Then, node, path)
+		}
+	}
+}
+
+// jsonType returns the JSON type of a YAML node
+func jsonType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+func matchesType(types []string, nodeType string) bool {
+	for _, t := range types {
+		if t == nodeType || (t == "number" && nodeType == "integer") {
+			return true
+		}
+	}
+	return false
+}
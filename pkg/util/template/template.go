@@ -0,0 +1,111 @@
+/*
+Package template Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Extension - the extension of template files, e.g. pipeline.yaml.tmpl
+const Extension = ".tmpl"
+
+// IsTemplate returns true if a file is a template
+func IsTemplate(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), Extension)
+}
+
+// Name returns the name of the file a template renders, without the template extension
+func Name(file string) string {
+	if IsTemplate(file) {
+		return file[:len(file)-len(Extension)]
+	}
+	return file
+}
+
+// LoadValues reads YAML values files and merges them, with values from later files taking precedence.
+// Maps are merged key by key, other values are replaced.
+func LoadValues(files []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var fileValues map[string]interface{}
+		if err := yaml.Unmarshal(content, &fileValues); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		merge(values, fileValues)
+	}
+	return values, nil
+}
+
+// merge merges src into dst
+func merge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			merge(dstMap, srcMap)
+		} else {
+			dst[key] = value
+		}
+	}
+}
+
+// Render renders the content of a template with values, available as {{ .Values.name }}. Referencing a
+// value that is not set is an error; optional values are read with index and given a default, e.g.
+// {{ index .Values "replicas" | default 1 }}.
+func Render(file string, content []byte, values map[string]interface{}) ([]byte, error) {
+	t, err := template.New(filepath.Base(file)).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, map[string]interface{}{"Values": values}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// funcs - the functions templates can use, in addition to the text/template built-ins
+var funcs = template.FuncMap{
+	// default returns value, or def if value is not set or empty
+	"default": func(def interface{}, value ...interface{}) interface{} {
+		if len(value) == 0 || value[0] == nil || value[0] == "" {
+			return def
+		}
+		return value[0]
+	},
+	// required returns value, or fails with message if value is not set or empty
+	"required": func(message string, value interface{}) (interface{}, error) {
+		if value == nil || value == "" {
+			return nil, errors.New(message)
+		}
+		return value, nil
+	},
+	// quote returns value as a double-quoted YAML string
+	"quote": func(value interface{}) string {
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	},
+	// toYaml returns value as YAML, without a trailing new line
+	"toYaml": func(value interface{}) (string, error) {
+		out, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(out), "\n"), err
+	},
+	// indent indents every line of text by spaces
+	"indent": func(spaces int, text string) string {
+		padding := strings.Repeat(" ", spaces)
+		return padding + strings.ReplaceAll(text, "\n", "\n"+padding)
+	},
+}
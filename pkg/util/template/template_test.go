@@ -0,0 +1,55 @@
+/*
+Package template Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package template
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	ioutil.WriteFile(base, []byte("project: Development\nendpoints:\n  k8s: k8s-dev\n  git: git\n"), 0644)
+	ioutil.WriteFile(prod, []byte("project: Production\nendpoints:\n  k8s: k8s-prod\ninputs:\n  replicas: 3\n"), 0644)
+	values, err := LoadValues([]string{base, prod})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := `project: {{ .Values.project }}
+k8s: {{ .Values.endpoints.k8s }}
+git: {{ .Values.endpoints.git | quote }}
+timeout: {{ index .Values "timeout" | default 30 }}
+input:
+{{ toYaml .Values.inputs | indent 2 }}
+`
+	out, err := Render("pipeline.yaml.tmpl", []byte(content), values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `project: Production
+k8s: k8s-prod
+git: "git"
+timeout: 30
+input:
+  replicas: 3
+`
+	if string(out) != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+
+	if _, err := Render("pipeline.yaml.tmpl", []byte("{{ .Values.missing }}"), values); err == nil {
+		t.Error("Render() of a missing value should fail")
+	}
+	if _, err := Render("pipeline.yaml.tmpl", []byte(`{{ required "name is required" (index .Values "name") }}`), values); err == nil {
+		t.Error("Render() of a missing required value should fail")
+	}
+	if Name("p.yaml.tmpl") != "p.yaml" || Name("p.yaml") != "p.yaml" || IsTemplate("p.yaml") {
+		t.Error("Name() or IsTemplate() is wrong")
+	}
+}
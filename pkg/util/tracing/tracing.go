@@ -0,0 +1,245 @@
+/*
+Package tracing Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/sammcgeown/vra-cli"
+
+// Attribute keys used on vra-cli spans
+const (
+	CommandKey   = attribute.Key("vra.command")
+	TargetKey    = attribute.Key("vra.target")
+	ServerKey    = attribute.Key("vra.server")
+	SubsystemKey = attribute.Key("vra.subsystem")
+	ObjectIDKey  = attribute.Key("vra.object.id")
+)
+
+// Options - tracing exporter configuration
+type Options struct {
+	Endpoint string // OTLP/HTTP endpoint (host:port), falls back to OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure bool   // Disable TLS for the OTLP endpoint
+	File     string // Write spans as JSON to this file
+	Version  string // vra-cli version reported on the resource
+}
+
+var (
+	provider    *sdktrace.TracerProvider
+	traceFile   *os.File
+	rootContext            = context.Background()
+	rootSpan    trace.Span = trace.SpanFromContext(context.Background())
+	uuidRegexp             = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+)
+
+// Init configures the global tracer provider. Tracing stays a no-op when neither
+// an OTLP endpoint nor a trace file is configured.
+func Init(options Options) error {
+	var exporters []sdktrace.SpanExporter
+
+	if options.Endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		var clientOptions []otlptracehttp.Option
+		if options.Endpoint != "" {
+			clientOptions = append(clientOptions, otlptracehttp.WithEndpoint(options.Endpoint))
+		}
+		if options.Insecure {
+			clientOptions = append(clientOptions, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), clientOptions...)
+		if err != nil {
+			return err
+		}
+		log.Debugln("Tracing: exporting spans via OTLP", options.Endpoint)
+		exporters = append(exporters, exporter)
+	}
+
+	if options.File != "" {
+		f, err := os.OpenFile(options.File, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return err
+		}
+		log.Debugln("Tracing: writing spans to", options.File)
+		traceFile = f
+		exporters = append(exporters, exporter)
+	}
+
+	if len(exporters) == 0 {
+		return nil
+	}
+
+	providerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String("vra-cli"),
+			semconv.ServiceVersionKey.String(options.Version),
+		)),
+	}
+	for _, exporter := range exporters {
+		providerOptions = append(providerOptions, sdktrace.WithBatcher(exporter))
+	}
+	provider = sdktrace.NewTracerProvider(providerOptions...)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Enabled returns true if spans are being exported
+func Enabled() bool {
+	return provider != nil
+}
+
+// Shutdown ends the command span and flushes any pending spans to the exporters
+func Shutdown() {
+	rootSpan.End()
+	if provider != nil {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			log.Debugln("Tracing: shutdown failed", err)
+		}
+		provider = nil
+	}
+	if traceFile != nil {
+		traceFile.Close()
+		traceFile = nil
+	}
+}
+
+// Flush exports any pending spans, leaving the exporters running
+func Flush() {
+	if provider != nil {
+		if err := provider.ForceFlush(context.Background()); err != nil {
+			log.Debugln("Tracing: flush failed", err)
+		}
+	}
+}
+
+// ExitHandler ends the command span as failed and flushes it, so that commands that exit with
+// log.Fatal are still traced. It is registered with logrus.RegisterExitHandler.
+func ExitHandler() {
+	rootSpan.SetStatus(codes.Error, "command failed")
+	rootSpan.End()
+	Flush()
+}
+
+// StartCommand starts the span that wraps the whole CLI invocation. Spans
+// started without an explicit parent are attached to it.
+func StartCommand(name string, attrs ...attribute.KeyValue) {
+	rootContext, rootSpan = otel.Tracer(tracerName).Start(context.Background(), name, trace.WithAttributes(attrs...))
+}
+
+// EndCommand ends the command span, leaving the exporters running for the next command
+func EndCommand() {
+	rootSpan.End()
+}
+
+// Annotate adds attributes to the command span, and optionally renames it
+func Annotate(name string, attrs ...attribute.KeyValue) {
+	if name != "" {
+		rootSpan.SetName(name)
+	}
+	rootSpan.SetAttributes(attrs...)
+}
+
+// Context returns the command context
+func Context() context.Context {
+	return rootContext
+}
+
+// Start starts a span as a child of ctx, or of the command span if ctx has no span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = rootContext
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err (if any) on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Subsystem maps an API path to the vRA subsystem that serves it
+func Subsystem(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/pipeline/"), strings.HasPrefix(path, "/codestream/"):
+		return "codestream"
+	case strings.HasPrefix(path, "/vco/"):
+		return "orchestrator"
+	case strings.HasPrefix(path, "/catalog/"), strings.HasPrefix(path, "/deployment/"):
+		return "servicebroker"
+	case strings.HasPrefix(path, "/csp/"):
+		return "identity"
+	case strings.HasPrefix(path, "/iaas/"), strings.HasPrefix(path, "/blueprint/"), strings.HasPrefix(path, "/properties/"),
+		strings.HasPrefix(path, "/project-service/"), strings.HasPrefix(path, "/provisioning/"):
+		return "cloudassembly"
+	}
+	return "unknown"
+}
+
+// ObjectIDs returns the object IDs referenced by an API path
+func ObjectIDs(path string) []string {
+	return uuidRegexp.FindAllString(path, -1)
+}
+
+// Transport wraps an http.RoundTripper so every request to the vRA API is recorded as a span
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if provider == nil {
+		return t.base.RoundTrip(req)
+	}
+	attrs := []attribute.KeyValue{
+		semconv.HTTPMethodKey.String(req.Method),
+		semconv.HTTPURLKey.String(req.URL.Scheme + "://" + req.URL.Host + req.URL.Path),
+		SubsystemKey.String(Subsystem(req.URL.Path)),
+	}
+	if ids := ObjectIDs(req.URL.Path); len(ids) > 0 {
+		attrs = append(attrs, ObjectIDKey.StringSlice(ids))
+	}
+	ctx, span := Start(req.Context(), req.Method+" "+req.URL.Path, attrs...)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		End(span, err)
+		return resp, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	span.End()
+	return resp, nil
+}
@@ -0,0 +1,58 @@
+/*
+Package tracing Copyright 2021 VMware, Inc.
+SPDX-License-Identifier: BSD-2-Clause
+*/
+package tracing
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubsystem(t *testing.T) {
+	cases := map[string]string{
+		"/pipeline/api/pipelines":   "codestream",
+		"/codestream/api/export":    "codestream",
+		"/vco/api/workflows":        "orchestrator",
+		"/catalog/api/items":        "servicebroker",
+		"/csp/gateway/am/api/login": "identity",
+		"/iaas/api/projects":        "cloudassembly",
+		"/blueprint/api/blueprints": "cloudassembly",
+		"/something/else":           "unknown",
+	}
+	for path, want := range cases {
+		if got := Subsystem(path); got != want {
+			t.Errorf("Subsystem(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestObjectIDs(t *testing.T) {
+	got := ObjectIDs("/pipeline/api/executions/bb3f6aff-311a-45fe-8081-5845a529068d/stages/71DCC4AA-FA43-4E66-A9C6-4D1D6DA2A4AB")
+	want := []string{"bb3f6aff-311a-45fe-8081-5845a529068d", "71DCC4AA-FA43-4E66-A9C6-4D1D6DA2A4AB"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ObjectIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestExitHandler(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spans.json")
+	if err := Init(Options{File: file, Version: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+	StartCommand("vra-cli get pipeline", CommandKey.String("vra-cli get pipeline"))
+
+	// A failing command exits without calling Shutdown, so the exit handler must export its span
+	ExitHandler()
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "vra-cli get pipeline") || !strings.Contains(string(content), `"Code":"Error"`) {
+		t.Errorf("the failed command span was not exported, spans: %s", content)
+	}
+}
@@ -43,6 +43,26 @@ type Executions struct {
 	Tags []string `json:"tags"`
 }
 
+// ExecutionStage - a stage of a Code Stream Execution
+type ExecutionStage struct {
+	Name             string                   `json:"name"`
+	Status           string                   `json:"status"`
+	StatusMessage    string                   `json:"statusMessage"`
+	TaskOrder        []string                 `json:"taskOrder"`
+	Tasks            map[string]ExecutionTask `json:"tasks"`
+	DurationInMicros int                      `json:"_durationInMicros"`
+}
+
+// ExecutionTask - a task of a Code Stream Execution stage
+type ExecutionTask struct {
+	Name             string      `json:"name"`
+	Type             string      `json:"type"`
+	Status           string      `json:"status"`
+	StatusMessage    string      `json:"statusMessage"`
+	Output           interface{} `json:"output"`
+	DurationInMicros int         `json:"_durationInMicros"`
+}
+
 // VariableResponse - Code Stream API Variable response
 type VariableResponse struct {
 	Project            string `json:"project"`
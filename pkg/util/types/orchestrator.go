@@ -10,15 +10,8 @@ import "time"
 
 // InventoryItemsList is a list of InventoryItems
 type InventoryItemsList struct {
-	Link []struct {
-		Attributes []struct {
-			Value string `json:"value"`
-			Name  string `json:"name"`
-		} `json:"attributes"`
-		Href string `json:"href"`
-		Rel  string `json:"rel"`
-	} `json:"link"`
-	Total int `json:"total"`
+	Link  []Link `json:"link"`
+	Total int    `json:"total"`
 }
 
 // WsWorkflow is a workflow
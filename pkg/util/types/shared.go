@@ -5,6 +5,8 @@ SPDX-License-Identifier: BSD-2-Clause
 package types
 
 import (
+	"time"
+
 	"github.com/go-resty/resty/v2"
 	"github.com/vmware/vra-sdk-go/pkg/client"
 )
@@ -34,8 +36,20 @@ type APIClientOptions struct {
 		Page     int
 		Skip     int
 	}
-	Config *Config
-	Output string
+	Config   *Config
+	Output   string
+	Parallel int           // Maximum number of concurrent API requests for fan-out lookups
+	Summary  bool          // Build list results from inventory attributes where possible, skipping per-object lookups
+	NoCache  bool          // Disable the local name to ID cache
+	CacheTTL time.Duration // How long cached lookups are valid for
+	DryRun   bool          // Print mutating requests instead of sending them
+	// Imports
+	OnConflict string // What to do when an imported object already exists - skip, overwrite, rename or fail
+	Atomic     bool   // Delete the objects created by an import if any object fails
+	// Integrity
+	SigningKey string // Name of the key used to sign exports
+	Integrity  string // Import policy - none, checksum or signed
+	Canonical  bool   // Write exports without server generated fields, with sorted keys and one file per object
 }
 
 // Exception - Generic exception struct